@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/cli"
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// printOffenders prints the table of files that exceeded the size budgets
+func printOffenders(offenders []processor.Offender, warning bool) {
+
+	color := cli.Fatal
+	label := "[ERROR]"
+
+	if warning {
+		color = cli.Warn
+		label = "[WARN]"
+	}
+
+	cli.Printf(color, "%s %d size budgets exceeded\n", label, len(offenders))
+	cli.Printf(color, "%-30s %-12s %-12s %-12s %s\n", "BUDGET", "SIZE", "LIMIT", "OVER", "FILES")
+
+	for _, offender := range offenders {
+
+		budget := strings.Join(offender.Budget.Patterns, ",")
+		if offender.Budget.Total {
+			budget += " (total)"
+		}
+		if offender.Budget.Gzip {
+			budget += " (gzip)"
+		}
+
+		cli.Printf(
+			color,
+			"%-30s %-12s %-12s %-12s %s\n",
+			budget,
+			system.FormatSize(offender.Size),
+			system.FormatSize(offender.Budget.Limit),
+			system.FormatSize(offender.Size-offender.Budget.Limit),
+			strings.Join(offender.Files, ", "),
+		)
+
+	}
+
+}
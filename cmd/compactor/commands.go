@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/cli"
+	"github.com/mateussouzaweb/compactor/src/server"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Command struct
+type Command struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, command *Command, args []string) int
+}
+
+var _commands []*Command
+
+// AddCommand append a new command to the list of available commands
+func AddCommand(command *Command) {
+	_commands = append(_commands, command)
+}
+
+// GetCommand retrieves the command that matches the name
+func GetCommand(name string) *Command {
+
+	for _, command := range _commands {
+		if command.Name == name {
+			return command
+		}
+	}
+
+	return nil
+}
+
+// printCommands prints the list of available commands
+func printCommands() {
+
+	cli.Printf("", "Usage: compactor [COMMAND] [OPTIONS]\n\n")
+	cli.Printf("", "Commands:\n")
+
+	for _, command := range _commands {
+		cli.Printf("", "  %-8s %s\n", command.Name, command.Description)
+	}
+
+	cli.Printf("", "\nRun compactor [COMMAND] --help to check the options of each command.\n")
+	cli.Printf("", "When no command is given, build is used.\n")
+
+}
+
+// usage prints the help text of the command with its flags
+func usage(command *Command, flags *flag.FlagSet) {
+
+	cli.Printf("", "Usage: compactor %s [OPTIONS]\n\n", command.Name)
+	cli.Printf("", "%s\n\n", command.Description)
+	cli.Printf("", "Options:\n")
+	flags.SetOutput(os.Stdout)
+	flags.PrintDefaults()
+
+	if command.Name == "build" {
+		cli.Printf("", "\n")
+		printCommands()
+	}
+
+}
+
+// parseError returns the exit code for the flags parsing error
+func parseError(err error) int {
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
+	return 2
+}
+
+// compile runs the build, watch and dev commands
+func compile(ctx context.Context, command *Command, args []string) int {
+
+	context, err := readContext(command, args)
+	if err != nil {
+		return parseError(err)
+	}
+
+	return run(ctx, context)
+}
+
+// serve runs the local server on the destination folder
+func serve(ctx context.Context, command *Command, args []string) int {
+
+	context, err := readContext(command, args)
+	if err != nil {
+		return parseError(err)
+	}
+
+	if !system.Exist(context.Destination) {
+		cli.Printf(cli.Fatal, "[ERROR] Files destination folder does not exists\n")
+		return 1
+	}
+
+	go func() {
+		cli.Printf(cli.Notice, "[INFO] Starting server at \033[1m%s\033[0m\n", "http://localhost:"+context.ServerPort)
+		err := server.Start(
+			context.Destination,
+			context.ServerPort,
+			func(uri string) error {
+				cli.Printf(cli.Notice, "[GET] %s\n", uri)
+				return nil
+			},
+		)
+		if err != nil {
+			cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+			os.Exit(1)
+		}
+	}()
+
+	<-ctx.Done()
+	cli.Printf(cli.Notice, "[INFO] Goodbye :)\n")
+
+	return 0
+}
+
+// clean removes the destination folder
+func clean(ctx context.Context, command *Command, args []string) int {
+
+	context, err := readContext(command, args)
+	if err != nil {
+		return parseError(err)
+	}
+
+	source, _ := os.Getwd()
+	destination := context.Destination
+
+	// Prevent the removal of the working directory or its parents
+	if strings.HasPrefix(source+"/", destination+"/") {
+		cli.Printf(cli.Fatal, "[ERROR] Refusing to remove %s\n", destination)
+		return 1
+	}
+
+	err = system.DeleteDirectory(destination)
+	if err != nil {
+		cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+		return 1
+	}
+
+	cli.Printf(cli.Success, "[DELETED] %s\n", destination)
+
+	return 0
+}
+
+// initialize creates a starter project on the source folder
+// Existing files are never overwritten
+func initialize(ctx context.Context, command *Command, args []string) int {
+
+	context, err := readContext(command, args)
+	if err != nil {
+		return parseError(err)
+	}
+
+	files := map[string]string{
+		"index.html": "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n" +
+			"  <meta charset=\"UTF-8\">\n" +
+			"  <meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n" +
+			"  <title>Compactor</title>\n" +
+			"  <link rel=\"stylesheet\" href=\"styles.scss\">\n" +
+			"</head>\n<body>\n" +
+			"  <h1>Hello world!</h1>\n" +
+			"  <script src=\"scripts.js\"></script>\n" +
+			"</body>\n</html>\n",
+		"styles.scss": "$color: #333;\n\nbody {\n  color: $color;\n}\n",
+		"scripts.js":  "console.log('Hello world!')\n",
+	}
+
+	for _, name := range []string{"index.html", "styles.scss", "scripts.js"} {
+
+		file := filepath.Join(context.Source, name)
+
+		if system.Exist(file) {
+			cli.Printf(cli.Warn, "[SKIPPED] %s already exists\n", file)
+			continue
+		}
+
+		err := system.EnsureDirectory(file)
+		if err != nil {
+			cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+			return 1
+		}
+
+		err = system.Write(file, files[name], 0644)
+		if err != nil {
+			cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+			return 1
+		}
+
+		cli.Printf(cli.Success, "[CREATED] %s\n", file)
+
+	}
+
+	return 0
+}
+
+// help prints the list of available commands
+func help(ctx context.Context, command *Command, args []string) int {
+
+	if len(args) > 0 && GetCommand(args[0]) != nil {
+		return GetCommand(args[0]).Run(ctx, GetCommand(args[0]), []string{"--help"})
+	}
+
+	printCommands()
+
+	return 0
+}
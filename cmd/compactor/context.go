@@ -2,22 +2,35 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
 )
 
 // Context struct
 type Context struct {
-	Version     bool
-	DebugMode   bool
-	WatchMode   bool
-	ServerMode  bool
-	ServerPort  string
-	Source      string
-	Destination string
-	Options     *processor.Options
+	Command         string
+	Version         bool
+	DebugMode       bool
+	WatchMode       bool
+	ServerMode      bool
+	ServerPort      string
+	DevelopMode     bool
+	SkipUnavailable bool
+	Summary         bool
+	FailFast        bool
+	Staging         bool
+	ReportFormat    string
+	ReportFile      string
+	Report          *Report
+	Source          string
+	Destination     string
+	Options         *processor.Options
 }
 
 // trueOrFalse returns if given value is likely to be a true or false flag
@@ -29,26 +42,21 @@ func trueOrFalse(value string) bool {
 	return false
 }
 
-// Read options from flags and arguments
-func readContext() *Context {
-
-	// Options
-	version := false
-	debug := false
-	watch := false
-	serverMode := false
-	serverPort := "5000"
+// defaultOptions returns the default compilation options
+func defaultOptions() *processor.Options {
 
 	source, _ := filepath.Abs("src/")
 	destination, _ := filepath.Abs("dist/")
 
-	options := &processor.Options{
+	return &processor.Options{
 		Source: processor.Source{
 			Path: source,
 		},
 		Destination: processor.Destination{
-			Path:   destination,
-			Hashed: true,
+			Path:      destination,
+			Hashed:    true,
+			Algorithm: "md5",
+			Length:    16,
 		},
 		Compress: processor.Compress{
 			Enabled: true,
@@ -59,66 +67,34 @@ func readContext() *Context {
 		Progressive: processor.Progressive{
 			Enabled: true,
 		},
-	}
-
-	// Version flag
-	flag.BoolVar(
-		&version,
-		"version",
-		false,
-		"Description: Print program version")
-
-	// Debug flag
-	flag.BoolVar(
-		&debug,
-		"debug",
-		false,
-		"Description: Print debug information")
-
-	// Develop flag
-	flag.Func(
-		"develop",
-		"Default: false\nFormat: [BOOLEAN]\nDescription: Enable or disable development mode. When enabled, disables hash, compression and progressive enhancements and enable watch and server.",
-		func(value string) error {
-
-			if trueOrFalse(value) {
-				watch = true
-				serverMode = true
-				options.Destination.Hashed = false
-				options.Compress.Enabled = false
-				options.Progressive.Enabled = false
-			}
-
-			return nil
+		Precompress: processor.Precompress{
+			Enabled:   false,
+			Formats:   []string{"gzip", "brotli"},
+			Threshold: 1024,
 		},
-	)
-
-	// Watch flag
-	flag.BoolVar(
-		&watch,
-		"watch",
-		false,
-		"Description: Enables file watching to live compile on code change")
-
-	// Server flag
-	flag.Func(
-		"server",
-		"Default: false\nFormats: [BOOLEAN] or :[PORT]\nDescription: Enable or disable local server on given port - if port is not specified, defaults to :5000. NOTE: Not existing paths will be automatically translated to index.html for a SPA like feature.",
-		func(value string) error {
+		ContentPolicy: processor.ContentPolicy{
+			Enabled: false,
+			Output:  "meta",
+		},
+		Inline: processor.Inline{
+			Enabled:   false,
+			Threshold: 4096,
+		},
+	}
+}
 
-			if strings.Contains(value, ":") {
-				serverMode = true
-				serverPort = strings.Replace(value, ":", "", 1)
-			} else {
-				serverMode = trueOrFalse(value)
-			}
+// developOptions disables hash, compression and progressive enhancements for development
+func developOptions(options *processor.Options) {
+	options.Destination.Hashed = false
+	options.Compress.Enabled = false
+	options.Progressive.Enabled = false
+}
 
-			return nil
-		},
-	)
+// compilationFlags adds the flags that defines the compilation options
+func compilationFlags(flags *flag.FlagSet, options *processor.Options) {
 
 	// Compilation flags
-	flag.Func(
+	flags.Func(
 		"source",
 		"Default: /src\nFormat: [PATH]\nDescription: Set the path of project source files",
 		func(path string) error {
@@ -131,7 +107,7 @@ func readContext() *Context {
 			return err
 		})
 
-	flag.Func(
+	flags.Func(
 		"include",
 		"Format: [PATTERN,...]\nDescription: Only include matching files from the given pattern",
 		func(value string) error {
@@ -140,7 +116,7 @@ func readContext() *Context {
 			return nil
 		})
 
-	flag.Func(
+	flags.Func(
 		"exclude",
 		"Format: [PATTERN,...]\nDescription: Exclude matching files from the given pattern",
 		func(value string) error {
@@ -149,7 +125,7 @@ func readContext() *Context {
 			return nil
 		})
 
-	flag.Func(
+	flags.Func(
 		"destination",
 		"Default: /dist\nFormat: [PATH]\nDescription: Set the path to the destination folder",
 		func(path string) error {
@@ -162,18 +138,72 @@ func readContext() *Context {
 			return err
 		})
 
-	flag.Func(
+	flags.Func(
 		"hashed",
-		"Default: true\nDescription: Defines if destination file should have the hash key in its name to avoid server caching on files that can be constantly updated.",
+		"Default: true\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if destination file should have the hash key in its name to avoid server caching on files that can be constantly updated. JS and CSS files are hashed by default, other files are only hashed when matching an included pattern.",
+		func(value string) error {
+
+			split := strings.Split(value, ":")
+			enabled := trueOrFalse(split[0])
+
+			if len(split) > 1 {
+
+				patterns := strings.Split(split[1], ",")
+
+				if enabled {
+					options.Destination.Include = append(
+						options.Destination.Include,
+						patterns...,
+					)
+				} else {
+					options.Destination.Exclude = append(
+						options.Destination.Exclude,
+						patterns...,
+					)
+				}
+
+			} else {
+				options.Destination.Hashed = enabled
+			}
+
+			return nil
+		})
+
+	flags.Func(
+		"hash-algorithm",
+		"Default: md5\nFormat: [md5|sha256|xxhash]\nDescription: Defines the algorithm used to generate the hash key from the final file content",
+		func(value string) error {
+
+			if value != "md5" && value != "sha256" && value != "xxhash" {
+				return fmt.Errorf("unknown hash algorithm: %s", value)
+			}
+
+			options.Destination.Algorithm = value
+			return nil
+		})
+
+	flags.Func(
+		"hash-length",
+		"Default: 16\nFormat: [NUMBER]\nDescription: Defines the number of characters of the hash key",
 		func(value string) error {
 
-			enabled := trueOrFalse(value)
-			options.Destination.Hashed = enabled
+			length, err := strconv.Atoi(value)
+			if err == nil {
+				options.Destination.Length = length
+			}
+
+			return err
+		})
 
+	flags.Func(
+		"hash-query",
+		"Default: false\nFormat: [BOOLEAN]\nDescription: Defines if the hash key should be added as query string on file references, like app.js?v=[HASH], instead of renaming the destination file",
+		func(value string) error {
+			options.Destination.Query = trueOrFalse(value)
 			return nil
 		})
 
-	flag.Func(
+	flags.Func(
 		"compress",
 		"Default: true\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if should compress or minify code/images to reduce size",
 		func(value string) error {
@@ -204,7 +234,7 @@ func readContext() *Context {
 			return nil
 		})
 
-	flag.Func(
+	flags.Func(
 		"source-map",
 		"Default: true\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if should include source map reference on file compilation",
 		func(value string) error {
@@ -235,7 +265,7 @@ func readContext() *Context {
 			return nil
 		})
 
-	flag.Func(
+	flags.Func(
 		"progressive",
 		"Default: true\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if should generate new images formats from original image format as progressive enhancement",
 		func(value string) error {
@@ -266,31 +296,553 @@ func readContext() *Context {
 			return nil
 		})
 
+	flags.Func(
+		"precompress",
+		"Default: false\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if should generate pre-compressed copies of text files (HTML, CSS, JS, JSON, XML, SVG and source maps) to be served as static compressed files. Patterns are matched against the destination files",
+		func(value string) error {
+
+			split := strings.Split(value, ":")
+			enabled := trueOrFalse(split[0])
+
+			if len(split) > 1 {
+
+				patterns := strings.Split(split[1], ",")
+
+				if enabled {
+					options.Precompress.Enabled = true
+					options.Precompress.Include = append(
+						options.Precompress.Include,
+						patterns...,
+					)
+				} else {
+					options.Precompress.Exclude = append(
+						options.Precompress.Exclude,
+						patterns...,
+					)
+				}
+
+			} else {
+				options.Precompress.Enabled = enabled
+			}
+
+			return nil
+		})
+
+	flags.Func(
+		"precompress-formats",
+		"Default: gzip,brotli\nFormat: [FORMAT,...]\nDescription: Defines which pre-compressed formats should be generated",
+		func(value string) error {
+
+			formats := strings.Split(value, ",")
+			for _, format := range formats {
+				if _, ok := processor.PrecompressFormats[format]; !ok {
+					return fmt.Errorf("unknown format: %s", format)
+				}
+			}
+
+			options.Precompress.Formats = formats
+			return nil
+		})
+
+	flags.Func(
+		"precompress-threshold",
+		"Default: 1024\nFormat: [BYTES]\nDescription: Defines the minimum file size to generate pre-compressed copies",
+		func(value string) error {
+
+			threshold, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				options.Precompress.Threshold = threshold
+			}
+
+			return err
+		})
+
+	flags.Func(
+		"integrity",
+		"Default: false\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if should add subresource integrity attributes on HTML scripts and stylesheets that matches the pattern",
+		func(value string) error {
+
+			split := strings.Split(value, ":")
+			enabled := trueOrFalse(split[0])
+
+			if len(split) > 1 {
+
+				patterns := strings.Split(split[1], ",")
+
+				if enabled {
+					options.Integrity.Enabled = true
+					options.Integrity.Include = append(
+						options.Integrity.Include,
+						patterns...,
+					)
+				} else {
+					options.Integrity.Exclude = append(
+						options.Integrity.Exclude,
+						patterns...,
+					)
+				}
+
+			} else {
+				options.Integrity.Enabled = enabled
+			}
+
+			return nil
+		})
+
+	flags.Func(
+		"crossorigin",
+		"Format: [anonymous|use-credentials]\nDescription: Defines the crossorigin attribute added with subresource integrity",
+		func(value string) error {
+
+			if value != "anonymous" && value != "use-credentials" {
+				return fmt.Errorf("unknown crossorigin value: %s", value)
+			}
+
+			options.Integrity.CrossOrigin = value
+			return nil
+		})
+
+	flags.Func(
+		"csp",
+		"Default: false\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if should generate content security policy hashes for inline scripts and styles of HTML pages",
+		func(value string) error {
+
+			split := strings.Split(value, ":")
+			enabled := trueOrFalse(split[0])
+
+			if len(split) > 1 {
+
+				patterns := strings.Split(split[1], ",")
+
+				if enabled {
+					options.ContentPolicy.Enabled = true
+					options.ContentPolicy.Include = append(
+						options.ContentPolicy.Include,
+						patterns...,
+					)
+				} else {
+					options.ContentPolicy.Exclude = append(
+						options.ContentPolicy.Exclude,
+						patterns...,
+					)
+				}
+
+			} else {
+				options.ContentPolicy.Enabled = enabled
+			}
+
+			return nil
+		})
+
+	flags.Func(
+		"csp-output",
+		"Default: meta\nFormat: [meta|headers]\nDescription: Defines where the content security policy is written: a meta tag injected on the page or a page.html.headers file beside the page",
+		func(value string) error {
+
+			if value != "meta" && value != "headers" {
+				return fmt.Errorf("unknown csp output: %s", value)
+			}
+
+			options.ContentPolicy.Output = value
+			return nil
+		})
+
+	flags.Func(
+		"critical",
+		"Default: false\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if should inline the critical CSS rules used by the HTML page elements and load the full stylesheets without blocking the page render",
+		func(value string) error {
+
+			split := strings.Split(value, ":")
+			enabled := trueOrFalse(split[0])
+
+			if len(split) > 1 {
+
+				patterns := strings.Split(split[1], ",")
+
+				if enabled {
+					options.Critical.Enabled = true
+					options.Critical.Include = append(
+						options.Critical.Include,
+						patterns...,
+					)
+				} else {
+					options.Critical.Exclude = append(
+						options.Critical.Exclude,
+						patterns...,
+					)
+				}
+
+			} else {
+				options.Critical.Enabled = enabled
+			}
+
+			return nil
+		})
+
+	flags.Func(
+		"inline",
+		"Default: false\nFormats: [BOOLEAN] or [PATTERN,...]:[BOOLEAN]\nDescription: Defines if should inline small files that matches the pattern: images and fonts are embedded as data URIs in HTML and CSS, while scripts and stylesheets are converted to inline tags in HTML. References with ?inline query or data-inline attribute are always inlined",
+		func(value string) error {
+
+			split := strings.Split(value, ":")
+			enabled := trueOrFalse(split[0])
+
+			if len(split) > 1 {
+
+				patterns := strings.Split(split[1], ",")
+
+				if enabled {
+					options.Inline.Enabled = true
+					options.Inline.Include = append(
+						options.Inline.Include,
+						patterns...,
+					)
+				} else {
+					options.Inline.Exclude = append(
+						options.Inline.Exclude,
+						patterns...,
+					)
+				}
+
+			} else {
+				options.Inline.Enabled = enabled
+			}
+
+			return nil
+		})
+
+	flags.Func(
+		"inline-threshold",
+		"Default: 4096\nFormat: [BYTES]\nDescription: Defines the maximum file size to be inlined",
+		func(value string) error {
+
+			threshold, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				options.Inline.Threshold = threshold
+			}
+
+			return err
+		})
+
+	// Timeout flag
+	flags.Func(
+		"timeout",
+		"Format: [DURATION]\nDescription: Set the maximum processing time of each package, like 30s or 2m. When expired, external commands are stopped and partial outputs are removed",
+		func(value string) error {
+
+			timeout, err := time.ParseDuration(value)
+			if err == nil {
+				options.Timeout = timeout
+			}
+
+			return err
+		})
+
+	// Budget flag
+	flags.Func(
+		"budget",
+		"Format: [PATTERN,...]:[SIZE][:total][:gzip]\nDescription: Set a size budget for the final outputs that matches the pattern, like *.js:150KB or index.html:30KB:gzip. With total, the sum of every matching file is compared, like *.png,*.jpg,*.webp:2MB:total. With gzip, the gzipped size is measured. Exceeded budgets fails the build, but only warns in development mode. Can be used multiple times",
+		func(value string) error {
+
+			split := strings.Split(value, ":")
+			if len(split) < 2 {
+				return fmt.Errorf("invalid budget: %s", value)
+			}
+
+			limit, err := system.ParseSize(split[1])
+			if err != nil {
+				return err
+			}
+
+			budget := processor.Budget{
+				Patterns: strings.Split(split[0], ","),
+				Limit:    limit,
+			}
+
+			for _, modifier := range split[2:] {
+				switch modifier {
+				case "total":
+					budget.Total = true
+				case "gzip":
+					budget.Gzip = true
+				default:
+					return fmt.Errorf("unknown budget modifier: %s", modifier)
+				}
+			}
+
+			options.Budgets = append(options.Budgets, budget)
+			return nil
+		})
+
+	// Tools flags
+	flags.Func(
+		"tool-path",
+		"Format: [TOOL=PATH,...]\nDescription: Set the executable path of external tools, like terser=./bin/terser. When not set, tools are searched on local node_modules/.bin folders and then on PATH",
+		func(value string) error {
+
+			for item := range strings.SplitSeq(value, ",") {
+
+				name, path, ok := strings.Cut(item, "=")
+				if !ok {
+					return fmt.Errorf("invalid tool path: %s", item)
+				}
+
+				if options.Tools.Path == nil {
+					options.Tools.Path = make(map[string]string)
+				}
+
+				options.Tools.Path[name] = path
+
+			}
+
+			return nil
+		})
+
+	flags.Func(
+		"tool-args",
+		"Format: [TOOL=ARGS]\nDescription: Add extra arguments to the external tool command, like \"cwebp=-m 6 -mt\". Can be used multiple times",
+		func(value string) error {
+
+			name, args, ok := strings.Cut(value, "=")
+			if !ok {
+				return fmt.Errorf("invalid tool arguments: %s", value)
+			}
+
+			if options.Tools.Args == nil {
+				options.Tools.Args = make(map[string][]string)
+			}
+
+			options.Tools.Args[name] = append(options.Tools.Args[name], strings.Fields(args)...)
+			return nil
+		})
+
+	flags.Func(
+		"tool-env",
+		"Format: [TOOL=KEY=VALUE]\nDescription: Add an environment variable to the external tool command, like node=NODE_OPTIONS=--max-old-space-size=4096. Can be used multiple times",
+		func(value string) error {
+
+			name, env, ok := strings.Cut(value, "=")
+			if !ok || !strings.Contains(env, "=") {
+				return fmt.Errorf("invalid tool environment: %s", value)
+			}
+
+			if options.Tools.Env == nil {
+				options.Tools.Env = make(map[string][]string)
+			}
+
+			options.Tools.Env[name] = append(options.Tools.Env[name], env)
+			return nil
+		})
+
+	flags.Func(
+		"node-modules",
+		"Format: [PATH]\nDescription: Set the node_modules folder used to load the Sass and TypeScript modules. When not set, local node_modules folders from the source path are used before the global folder",
+		func(path string) error {
+
+			folder, err := filepath.Abs(path)
+			if err == nil {
+				options.Tools.NodeModules = folder
+			}
+
+			return err
+		})
+
 	// Plugin flag
-	flag.Func(
+	flags.Func(
 		"disable",
 		"Format: [PLUGIN,...]\nDescription: Defines which plugin should be disabled. When a plugin is disabled, the next available plugin that matches the file extension will be used. Otherwise, it forces the use of the generic plugin (simple copy to destination)",
 		func(value string) error {
 
 			list := strings.SplitSeq(value, ",")
 			for namespace := range list {
-				processor.RemovePlugin(namespace)
+				options.Builder().RemovePlugin(namespace)
 			}
 
 			return nil
 		})
 
+}
+
+// Read options from flags and arguments of the command
+// Running without command fallback to build, which also accepts the legacy mode flags
+func readContext(command *Command, args []string) (*Context, error) {
+
+	context := &Context{
+		Command:      command.Name,
+		ServerPort:   "5000",
+		ReportFormat: "text",
+		Options:      defaultOptions(),
+	}
+
+	options := context.Options
+	flags := flag.NewFlagSet(command.Name, flag.ContinueOnError)
+	flags.Usage = func() {
+		usage(command, flags)
+	}
+
+	// Debug flag
+	flags.BoolVar(
+		&context.DebugMode,
+		"debug",
+		false,
+		"Description: Print debug information")
+
+	switch command.Name {
+	case "build":
+
+		// Version flag
+		flags.BoolVar(
+			&context.Version,
+			"version",
+			false,
+			"Description: Print program version")
+
+		// Develop flag
+		flags.Func(
+			"develop",
+			"Default: false\nFormat: [BOOLEAN]\nDescription: Enable or disable development mode. When enabled, disables hash, compression and progressive enhancements and enable watch and server. Prefer the dev command.",
+			func(value string) error {
+
+				if trueOrFalse(value) {
+					context.DevelopMode = true
+					context.WatchMode = true
+					context.ServerMode = true
+					developOptions(options)
+				}
+
+				return nil
+			},
+		)
+
+		// Watch flag
+		flags.BoolVar(
+			&context.WatchMode,
+			"watch",
+			false,
+			"Description: Enables file watching to live compile on code change. Prefer the watch command.")
+
+		// Server flag
+		flags.Func(
+			"server",
+			"Default: false\nFormats: [BOOLEAN] or :[PORT]\nDescription: Enable or disable local server on given port - if port is not specified, defaults to :5000. Prefer the serve or dev command.",
+			func(value string) error {
+
+				if strings.Contains(value, ":") {
+					context.ServerMode = true
+					context.ServerPort = strings.Replace(value, ":", "", 1)
+				} else {
+					context.ServerMode = trueOrFalse(value)
+				}
+
+				return nil
+			},
+		)
+
+	case "watch":
+		context.WatchMode = true
+
+	case "dev":
+		context.DevelopMode = true
+		context.WatchMode = true
+		context.ServerMode = true
+		developOptions(options)
+
+	}
+
+	// Port flag
+	// Server mode can be enabled by flags, so usage is validated after parsing
+	if command.Name == "build" || command.Name == "dev" || command.Name == "serve" {
+		flags.StringVar(
+			&context.ServerPort,
+			"port",
+			context.ServerPort,
+			"Description: Set the port of the local server. NOTE: Not existing paths will be automatically translated to index.html for a SPA like feature.")
+	}
+
+	// Serve and clean only works with the destination folder
+	if command.Name == "serve" || command.Name == "clean" {
+		flags.Func(
+			"destination",
+			"Default: /dist\nFormat: [PATH]\nDescription: Set the path to the destination folder",
+			func(path string) error {
+
+				destination, err := filepath.Abs(path)
+				if err == nil {
+					options.Destination.Path = destination
+				}
+
+				return err
+			})
+	} else {
+		compilationFlags(flags, options)
+	}
+
+	// Plugins with missing tools can only be skipped on compilation
+	if command.Name == "build" || command.Name == "watch" || command.Name == "dev" {
+		flags.BoolVar(
+			&context.SkipUnavailable,
+			"skip-unavailable",
+			false,
+			"Description: Disable plugins with missing external tools. Files are then processed by the next available plugin that matches the file extension or the generic plugin (simple copy to destination). Check the tools with the doctor command")
+
+		flags.BoolVar(
+			&context.FailFast,
+			"fail-fast",
+			false,
+			"Description: Stop the compilation on the first package error")
+
+		flags.BoolVar(
+			&context.Staging,
+			"staging",
+			false,
+			"Description: Write the outputs into a staging folder beside the destination folder, which replaces the destination only when the build succeeds. Not available with watch or server")
+
+		flags.BoolVar(
+			&context.Summary,
+			"summary",
+			true,
+			"Default: true\nDescription: Print the summary table of sizes, savings and time for each package and plugin after compilation")
+
+		flags.Func(
+			"report",
+			"Default: text\nFormat: [text|json|ndjson]\nDescription: Set the format of the build report. JSON prints a single document with every package event and the summary after compilation, while NDJSON prints one event per line as soon as it happens. When not writing to a file, the text output is moved to the standard error",
+			func(value string) error {
+
+				if value != "text" && value != "json" && value != "ndjson" {
+					return fmt.Errorf("unknown report format: %s", value)
+				}
+
+				context.ReportFormat = value
+				return nil
+			})
+
+		flags.StringVar(
+			&context.ReportFile,
+			"report-file",
+			"",
+			"Format: [PATH]\nDescription: Write the build report to the given file instead of the standard output")
+	}
+
 	// Parse values
-	flag.Parse()
-
-	return &Context{
-		Version:     version,
-		DebugMode:   debug,
-		WatchMode:   watch,
-		ServerMode:  serverMode,
-		ServerPort:  serverPort,
-		Source:      source,
-		Destination: destination,
-		Options:     options,
+	err := flags.Parse(args)
+	if err != nil {
+		return context, err
 	}
+
+	flags.Visit(func(flag *flag.Flag) {
+		if flag.Name == "port" && !context.ServerMode && command.Name != "serve" {
+			err = fmt.Errorf("port is only available with server mode, use --server true")
+		}
+	})
+
+	// Reported like the parsing errors
+	if err != nil {
+		fmt.Fprintln(flags.Output(), err)
+		flags.Usage()
+	}
+
+	context.Source = options.Source.Path
+	context.Destination = options.Destination.Path
+
+	return context, err
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+
+	"github.com/mateussouzaweb/compactor/src/cli"
+	"github.com/mateussouzaweb/compactor/src/processor"
+)
+
+// doctor checks the availability of the external tools required by each plugin
+func doctor(ctx context.Context, command *Command, args []string) int {
+
+	context, err := readContext(command, args)
+	if err != nil {
+		return parseError(err)
+	}
+
+	options := context.Options
+	missing := 0
+
+	for _, plugin := range options.Builder().GetPlugins() {
+
+		cli.Printf(cli.Purple, "[%s]\n", plugin.Namespace)
+
+		if len(plugin.Tools) == 0 {
+			cli.Printf(cli.Notice, "  No external tools required\n")
+			continue
+		}
+
+		for _, tool := range plugin.Tools {
+
+			status := processor.CheckTool(options, tool)
+
+			if status.Found {
+				cli.Printf(cli.Success, "  [OK] %s", tool.Name)
+				cli.Printf("", " %s - %s\n", status.Version, status.Path)
+				continue
+			}
+
+			if tool.Optional {
+				cli.Printf(cli.Warn, "  [OPTIONAL] %s not found - install with: %s\n", tool.Name, tool.Install)
+				continue
+			}
+
+			missing++
+			cli.Printf(cli.Fatal, "  [MISSING] %s not found - install with: %s\n", tool.Name, tool.Install)
+
+		}
+
+	}
+
+	if missing > 0 {
+		cli.Printf(cli.Fatal, "\n[ERROR] %d required tools are missing. Install them or use --skip-unavailable to process these files with the generic plugin\n", missing)
+		return 1
+	}
+
+	cli.Printf(cli.Success, "\n[INFO] All required tools are available\n")
+
+	return 0
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/cli"
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// GraphContext struct
+type GraphContext struct {
+	Format  string
+	Depends string
+	Options *processor.Options
+}
+
+// Read graph options from flags and arguments
+func readGraphContext(command *Command, args []string) (*GraphContext, error) {
+
+	context := &GraphContext{
+		Format:  "text",
+		Options: defaultOptions(),
+	}
+
+	flags := flag.NewFlagSet(command.Name, flag.ContinueOnError)
+	flags.Usage = func() {
+		usage(command, flags)
+	}
+
+	// Accepts the same compilation flags of build, so the graph matches the project setup
+	compilationFlags(flags, context.Options)
+
+	flags.Func(
+		"format",
+		"Default: text\nFormat: [text|dot|json]\nDescription: Set the output format of the graph",
+		func(value string) error {
+
+			if value != "text" && value != "dot" && value != "json" {
+				return fmt.Errorf("unknown format: %s", value)
+			}
+
+			context.Format = value
+			return nil
+		})
+
+	flags.StringVar(
+		&context.Depends,
+		"depends",
+		"",
+		"Format: [PATH]\nDescription: Only print the packages that depends on the given file path. The path is relative to the working directory or, when not found there, to the source folder")
+
+	err := flags.Parse(args)
+
+	return context, err
+}
+
+// printTree prints the related files of the file recursively as a text tree
+func printTree(options *processor.Options, file *processor.File, prefix string, visited map[string]bool) {
+
+	var items []processor.Related
+	for _, related := range file.Related {
+		if related.File.Path != "" {
+			items = append(items, related)
+		}
+	}
+
+	for index, related := range items {
+
+		branch, next := "├── ", "│   "
+		if index == len(items)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		label := related.Type
+		if related.Dependency {
+			label += ", dependency"
+		}
+		if processor.IsGenerated(options, related) {
+			label += ", generated"
+		}
+
+		path := options.CleanPath(related.File.Path)
+		cli.Printf(cli.Notice, "%s%s", prefix, branch)
+		cli.Printf("", "%s ", path)
+		cli.Printf(cli.Purple, "[%s]", label)
+
+		if visited[related.File.Path] {
+			cli.Printf(cli.Fatal, " (cycle)\n")
+			continue
+		}
+
+		cli.Printf("", "\n")
+
+		visited[related.File.Path] = true
+		printTree(options, related.File, prefix+next, visited)
+		visited[related.File.Path] = false
+
+	}
+
+}
+
+// printGraphText prints the graph as a text tree for each package
+func printGraphText(options *processor.Options, packages []*processor.File) {
+
+	for _, file := range packages {
+		cli.Printf(cli.Success, "%s\n", options.CleanPath(file.Path))
+		printTree(options, file, "", map[string]bool{file.Path: true})
+	}
+
+	graph := processor.BuildGraph(options)
+
+	if len(graph.Orphans) > 0 {
+		cli.Printf(cli.Warn, "\nOrphaned files (not referenced by any page or file):\n")
+		for _, path := range graph.Orphans {
+			cli.Printf(cli.Warn, "  %s\n", path)
+		}
+	}
+
+	if len(graph.Cycles) > 0 {
+		cli.Printf(cli.Fatal, "\nCircular references:\n")
+		for _, cycle := range graph.Cycles {
+			cli.Printf(cli.Fatal, "  %s\n", strings.Join(cycle, " -> "))
+		}
+	}
+
+}
+
+// printGraphDOT prints the graph in the DOT language
+func printGraphDOT(options *processor.Options) {
+
+	graph := processor.BuildGraph(options)
+
+	fmt.Println("digraph compactor {")
+	fmt.Println("  rankdir=LR;")
+	fmt.Println("  node [shape=box];")
+
+	for _, path := range graph.Packages {
+		fmt.Printf("  %q [style=bold];\n", path)
+	}
+	for _, path := range graph.Orphans {
+		fmt.Printf("  %q [color=orange];\n", path)
+	}
+
+	for _, edge := range graph.Edges {
+
+		style := "dashed"
+		if edge.Dependency {
+			style = "solid"
+		}
+
+		attributes := fmt.Sprintf("label=%q, style=%s", edge.Type, style)
+		if edge.Generated {
+			attributes += ", color=gray"
+		}
+
+		fmt.Printf("  %q -> %q [%s];\n", edge.From, edge.To, attributes)
+
+	}
+
+	for _, cycle := range graph.Cycles {
+		for index := 0; index < len(cycle)-1; index++ {
+			fmt.Printf("  %q -> %q [color=red];\n", cycle[index], cycle[index+1])
+		}
+	}
+
+	fmt.Println("}")
+
+}
+
+// printGraphJSON prints the graph as JSON
+func printGraphJSON(options *processor.Options) error {
+
+	graph := processor.BuildGraph(options)
+
+	content, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(content))
+
+	return nil
+}
+
+// graph runs the graph inspection command
+func graph(ctx context.Context, command *Command, args []string) int {
+
+	context, err := readGraphContext(command, args)
+	if err != nil {
+		return parseError(err)
+	}
+
+	options := context.Options
+
+	err = processor.IndexFiles(options, options.Source.Path)
+	if err != nil {
+		cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+		return 1
+	}
+
+	packages := processor.FindPackages(options)
+
+	if context.Depends != "" {
+
+		// Paths from the working directory have priority over paths from the source folder
+		path := context.Depends
+		if absolute, err := filepath.Abs(path); err == nil && system.Exist(absolute) {
+			path = absolute
+		}
+
+		dependents := processor.FindDependents(options, path)
+
+		if context.Format == "json" {
+			paths := []string{}
+			for _, file := range dependents {
+				paths = append(paths, options.CleanPath(file.Path))
+			}
+
+			content, err := json.MarshalIndent(paths, "", "  ")
+			if err != nil {
+				cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+				return 1
+			}
+
+			fmt.Println(string(content))
+			return 0
+		}
+
+		for _, file := range dependents {
+			fmt.Println(options.CleanPath(file.Path))
+		}
+
+		return 0
+	}
+
+	switch context.Format {
+	case "dot":
+		printGraphDOT(options)
+	case "json":
+		err = printGraphJSON(options)
+	default:
+		printGraphText(options, packages)
+	}
+
+	if err != nil {
+		cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+		return 1
+	}
+
+	return 0
+}
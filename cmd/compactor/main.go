@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/mateussouzaweb/compactor/src/cli"
+	"github.com/mateussouzaweb/compactor/src/errors"
 	"github.com/mateussouzaweb/compactor/src/plugins/css"
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
 	"github.com/mateussouzaweb/compactor/src/plugins/gif"
 	"github.com/mateussouzaweb/compactor/src/plugins/html"
+	"github.com/mateussouzaweb/compactor/src/plugins/ico"
 	"github.com/mateussouzaweb/compactor/src/plugins/javascript"
 	"github.com/mateussouzaweb/compactor/src/plugins/jpeg"
 	"github.com/mateussouzaweb/compactor/src/plugins/json"
@@ -26,17 +32,22 @@ import (
 )
 
 // process runs the package processing on the destination plugin
-func process(options *processor.Options, file *processor.File) error {
+func process(ctx context.Context, context *Context, file *processor.File) error {
+
+	options := context.Options
+	context.Report.Started(options, file)
 
 	start := time.Now().UnixNano() / int64(time.Millisecond)
-	err := processor.Process(options, file)
+	err := processor.Process(ctx, options, file)
 
 	end := time.Now().UnixNano() / int64(time.Millisecond)
 	processTime := end - start
 
+	context.Report.Finished(options, file, processTime, err)
+
 	if err != nil {
 		cli.Printf(cli.Fatal, "[ERROR] %s - %dms\n%v\n", file.Location, processTime, err)
-		return err
+		return fmt.Errorf("%s: %w", file.Location, err)
 	}
 
 	cli.Printf(cli.Success, "[PROCESSED] %s - %dms\n", file.Location, processTime)
@@ -65,7 +76,6 @@ func main() {
 	// processor.AddPlugin("styl", stylus.Plugin())
 	// processor.AddPlugin("apng", apng.Plugin())
 	// processor.AddPlugin("avif", avif.Plugin())
-	// processor.AddPlugin("ico", ico.Plugin())
 	// processor.AddPlugin("js", babel.Plugin())
 	// processor.AddPlugin("js", react.Plugin())
 	// processor.AddPlugin("jsx", react.Plugin())
@@ -95,16 +105,112 @@ func main() {
 	processor.AddPlugin(jpeg.Plugin())
 	processor.AddPlugin(png.Plugin())
 	processor.AddPlugin(webp.Plugin())
+	processor.AddPlugin(ico.Plugin())
 	processor.AddPlugin(generic.Plugin())
 
-	// Read options from arguments
-	context := readContext()
+	// Commands
+	AddCommand(&Command{
+		Name:        "build",
+		Description: "Compile the source files into the destination folder",
+		Run:         compile,
+	})
+	AddCommand(&Command{
+		Name:        "watch",
+		Description: "Compile the source files and live compile on code change",
+		Run:         compile,
+	})
+	AddCommand(&Command{
+		Name:        "serve",
+		Description: "Start a local server for the destination folder",
+		Run:         serve,
+	})
+	AddCommand(&Command{
+		Name:        "dev",
+		Description: "Run development mode: compile without hash, compression and progressive enhancements, watch changes and start the local server",
+		Run:         compile,
+	})
+	AddCommand(&Command{
+		Name:        "clean",
+		Description: "Remove the destination folder",
+		Run:         clean,
+	})
+	AddCommand(&Command{
+		Name:        "graph",
+		Description: "Print the dependency graph of the source files",
+		Run:         graph,
+	})
+	AddCommand(&Command{
+		Name:        "doctor",
+		Description: "Check the external tools required by each plugin",
+		Run:         doctor,
+	})
+	AddCommand(&Command{
+		Name:        "init",
+		Description: "Create a starter project on the source folder",
+		Run:         initialize,
+	})
+	AddCommand(&Command{
+		Name:        "help",
+		Description: "Print the list of commands or the help of the given command",
+		Run:         help,
+	})
+
+	// Detect command, defaults to build
+	name := "build"
+	args := os.Args[1:]
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = args[0]
+		args = args[1:]
+	}
+
+	command := GetCommand(name)
+	if command == nil {
+		cli.Printf(cli.Fatal, "[ERROR] Unknown command: %s\n\n", name)
+		printCommands()
+		os.Exit(2)
+		return
+	}
+
+	// Interruption cancels the processing gracefully
+	// A second interruption stops the program immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	os.Exit(command.Run(ctx, command, args))
+
+}
+
+// run runs the compilation process with the given context
+// Compilation stops when ctx is cancelled, removing partial outputs of the current package
+func run(ctx context.Context, context *Context) int {
+
 	options := context.Options
 
+	// Build report
+	var writer io.Writer = os.Stdout
+
+	if context.ReportFile != "" {
+		file, err := os.Create(context.ReportFile)
+		if err != nil {
+			cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+			return 1
+		}
+		writer = file
+	} else if context.ReportFormat != "text" {
+		cli.Output = os.Stderr
+	}
+
+	context.Report = NewReport(context.ReportFormat, writer)
+	defer context.Report.Close()
+
 	// Print information
 	if context.Version {
 		cli.Printf("", "Compactor version 0.3.7\n")
-		return
+		return 0
 	}
 
 	cli.Printf(cli.Purple, ":::| COMPACTOR - 0.3.7 |:::\n")
@@ -113,27 +219,43 @@ func main() {
 
 	if !system.Exist(options.Source.Path) {
 		cli.Printf(cli.Fatal, "[ERROR] Files source folder does not exists\n")
-		os.Exit(1)
-		return
+		return 1
 	}
 
-	// Start a signal watcher to capture program interrupt
-	exit := make(chan os.Signal, 1)
-	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
+	// Disable plugins with missing tools
+	if context.SkipUnavailable {
+		for _, namespace := range processor.DisableUnavailable(options) {
+			cli.Printf(cli.Warn, "[WARN] Plugin %s disabled due to missing tools\n", namespace)
+		}
+	}
 
-	go func() {
-		<-exit
-		shutdown(options)
-		cli.Printf(cli.Notice, "[INFO] Goodbye :)")
-		os.Exit(0)
-	}()
+	// Staging build
+	// Outputs are written into a staging folder that replaces the destination only on success
+	destination := options.Destination.Path
+	staging := context.Staging && !context.WatchMode && !context.ServerMode
+
+	if context.Staging && !staging {
+		cli.Printf(cli.Warn, "[WARN] Staging is only available on builds without watch or server\n")
+	}
+
+	if staging {
+
+		options.Destination.Path = destination + ".staging"
+		cli.Printf(cli.Notice, "[INFO] Files staging folder is %s\n", options.Destination.Path)
+
+		err := system.DeleteDirectory(options.Destination.Path)
+		if err != nil {
+			cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+			return 1
+		}
+
+	}
 
 	// Index source files
 	err := processor.IndexFiles(options, options.Source.Path)
 	if err != nil {
 		cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
-		os.Exit(1)
-		return
+		return 1
 	}
 
 	// Detect packages
@@ -148,12 +270,17 @@ func main() {
 		cli.Printf(cli.Notice, "[DEBUG] Compress ==> %+v\n", options.Compress)
 		cli.Printf(cli.Notice, "[DEBUG] SourceMap ==> %+v\n", options.SourceMap)
 		cli.Printf(cli.Notice, "[DEBUG] Progressive ==> %+v\n", options.Progressive)
+		cli.Printf(cli.Notice, "[DEBUG] Precompress ==> %+v\n", options.Precompress)
+		cli.Printf(cli.Notice, "[DEBUG] Integrity ==> %+v\n", options.Integrity)
+		cli.Printf(cli.Notice, "[DEBUG] ContentPolicy ==> %+v\n", options.ContentPolicy)
+		cli.Printf(cli.Notice, "[DEBUG] Critical ==> %+v\n", options.Critical)
+		cli.Printf(cli.Notice, "[DEBUG] Inline ==> %+v\n", options.Inline)
 		cli.Printf(cli.Notice, "[DEBUG] Watch ==> %+v\n", context.WatchMode)
 		cli.Printf(cli.Notice, "[DEBUG] Server ==> %+v\n", context.ServerMode)
 		cli.Printf(cli.Notice, "[DEBUG] Server Port ==> %+v\n", context.ServerPort)
 
 		cli.Printf(cli.Purple, "[DEBUG] --- INDEXED FILES ---\n")
-		for _, file := range processor.GetFiles() {
+		for _, file := range options.Builder().GetFiles() {
 			cli.Printf(cli.Notice, "[DEBUG] %s\n", options.CleanPath(file.Path))
 		}
 
@@ -205,7 +332,7 @@ func main() {
 						return nil
 					}
 
-					err := process(options, file)
+					err := process(ctx, context, file)
 					if err != nil {
 						return err
 					}
@@ -218,13 +345,14 @@ func main() {
 							continue
 						}
 
-						// Check on related items of the package
-						for _, related := range thePackage.Related {
+						// Check on related items of the package, including from its dependencies
+						for _, related := range thePackage.FindRelated(false) {
 							if !related.Dependency && related.File.Path == file.Path {
-								err := process(options, thePackage)
+								err := process(ctx, context, thePackage)
 								if err != nil {
 									return err
 								}
+								break
 							}
 						}
 
@@ -240,16 +368,105 @@ func main() {
 	// Compilation
 	cli.Printf(cli.Notice, "[INFO] Running compilation on each package\n")
 
+	// Errors are collected to fail the build at the end
+	// With fail fast, compilation stops on the first error
+	var failures error
+
 	for _, item := range packages {
-		process(options, item)
+		if ctx.Err() != nil {
+			break
+		}
+
+		errors.Join(&failures, func() error {
+			return process(ctx, context, item)
+		})
+
+		if failures != nil && context.FailFast {
+			break
+		}
+	}
+
+	// Interrupted compilation
+	if ctx.Err() != nil {
+		cli.Printf(cli.Warn, "[WARN] Compilation interrupted\n")
+		if staging {
+			system.DeleteDirectory(options.Destination.Path)
+		}
+		context.Report.Flush()
+		shutdown(options)
+		return 130
+	}
+
+	// Build summary
+	if context.Summary {
+		printSummary(context.Report)
+	}
+
+	// Error summary
+	// Failures only exits with error code when not running continuously
+	code := 0
+
+	if failures != nil {
+
+		list := errors.List(failures)
+		cli.Printf(cli.Fatal, "\n[ERROR] %d packages failed to compile\n", len(list))
+		for _, err := range list {
+			cli.Printf(cli.Fatal, "  %s\n", strings.SplitN(err.Error(), "\n", 2)[0])
+		}
+
+		if !context.WatchMode && !context.ServerMode {
+			code = 1
+		}
+
+	}
+
+	// Size budgets
+	// Exceeded budgets only fails the build outside development mode
+	offenders := processor.CheckBudgets(options, packages)
+
+	if len(offenders) > 0 {
+
+		printOffenders(offenders, context.DevelopMode)
+		for _, offender := range offenders {
+			context.Report.Exceeded(offender)
+		}
+
+		if !context.DevelopMode {
+			code = 1
+		}
+
 	}
 
+	// Swap staging folder into destination
+	if staging {
+
+		if code == 0 {
+			err := system.SwapDirectory(options.Destination.Path, destination)
+			if err != nil {
+				cli.Printf(cli.Fatal, "[ERROR] %v\n", err)
+				code = 1
+			} else {
+				cli.Printf(cli.Notice, "[INFO] Staging folder swapped into destination folder\n")
+			}
+		} else {
+			system.DeleteDirectory(options.Destination.Path)
+			cli.Printf(cli.Warn, "[WARN] Build failed, destination folder was kept unchanged\n")
+		}
+
+		options.Destination.Path = destination
+
+	}
+
+	context.Report.Flush()
+
 	// Keep process alive
 	if context.WatchMode || context.ServerMode {
-		<-exit
+		<-ctx.Done()
+		cli.Printf(cli.Notice, "[INFO] Goodbye :)\n")
 	}
 
 	// Shutdown
 	shutdown(options)
 
+	return code
 }
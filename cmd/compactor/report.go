@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Event struct
+type Event struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	File       string    `json:"file,omitempty"`
+	Plugin     string    `json:"plugin,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	InputSize  int64     `json:"inputSize,omitempty"`
+	OutputSize int64     `json:"outputSize,omitempty"`
+	Duration   int64     `json:"duration,omitempty"`
+	Limit      int64     `json:"limit,omitempty"`
+	Generated  []string  `json:"generated,omitempty"`
+	Files      []string  `json:"files,omitempty"`
+}
+
+// Summary struct
+type Summary struct {
+	Packages   int   `json:"packages"`
+	Succeeded  int   `json:"succeeded"`
+	Failed     int   `json:"failed"`
+	InputSize  int64 `json:"inputSize"`
+	OutputSize int64 `json:"outputSize"`
+	Duration   int64 `json:"duration"`
+}
+
+// Report struct
+type Report struct {
+	Format  string
+	Writer  io.Writer
+	Events  []Event
+	Summary Summary
+	Start   time.Time
+	mutex   sync.Mutex
+}
+
+// NewReport creates a new report for the given format
+// Text format only prints the colored output, but events are still kept for the build summary
+func NewReport(format string, writer io.Writer) *Report {
+	return &Report{
+		Format: format,
+		Writer: writer,
+		Events: []Event{},
+		Start:  time.Now(),
+	}
+}
+
+// write prints the value as a single JSON line
+func (r *Report) write(value any) {
+	content, _ := json.Marshal(value)
+	r.Writer.Write(append(content, '\n'))
+}
+
+// Add appends the event to the report
+// On NDJSON format, events are printed immediately
+func (r *Report) Add(event Event) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	event.Time = time.Now()
+	r.Events = append(r.Events, event)
+
+	if r.Format == "ndjson" {
+		r.write(event)
+	}
+
+}
+
+// Started registers the start of the file processing
+func (r *Report) Started(options *processor.Options, file *processor.File) {
+	r.Add(Event{
+		Type:   "start",
+		File:   options.CleanPath(file.Path),
+		Plugin: options.Builder().GetPlugin(file.Extension).Namespace,
+	})
+}
+
+// Finished registers the result of the file processing
+// Input size includes the size of the existing dependencies, like imports and sprite icons
+func (r *Report) Finished(options *processor.Options, file *processor.File, duration int64, err error) {
+
+	event := Event{
+		Type:      "success",
+		File:      options.CleanPath(file.Path),
+		Plugin:    options.Builder().GetPlugin(file.Extension).Namespace,
+		InputSize: system.Size(file.Path),
+		Duration:  duration,
+	}
+
+	for _, related := range file.FindRelated(true) {
+		if related.File.Exists && related.File.Path != file.Path {
+			event.InputSize += system.Size(related.File.Path)
+		}
+	}
+
+	if err != nil {
+		event.Type = "error"
+		event.Message = err.Error()
+	} else {
+		event.OutputSize = system.Size(file.Destination)
+		for _, output := range processor.Outputs(file) {
+			if output != file.Destination {
+				event.Generated = append(event.Generated, options.CleanPath(output))
+			}
+		}
+	}
+
+	r.mutex.Lock()
+	r.Summary.Packages++
+	r.Summary.InputSize += event.InputSize
+	r.Summary.OutputSize += event.OutputSize
+	if err != nil {
+		r.Summary.Failed++
+	} else {
+		r.Summary.Succeeded++
+	}
+	r.mutex.Unlock()
+
+	r.Add(event)
+
+}
+
+// Exceeded registers the size budget exceeded by the files
+func (r *Report) Exceeded(offender processor.Offender) {
+	r.Add(Event{
+		Type:       "budget",
+		Message:    "size budget exceeded for " + strings.Join(offender.Budget.Patterns, ","),
+		OutputSize: offender.Size,
+		Limit:      offender.Budget.Limit,
+		Files:      offender.Files,
+	})
+}
+
+// Flush prints the final summary of the report
+// On JSON format, the whole report is printed as a single document
+func (r *Report) Flush() {
+
+	if r.Format == "text" {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.Summary.Duration = time.Since(r.Start).Milliseconds()
+
+	if r.Format == "ndjson" {
+		r.write(struct {
+			Type string `json:"type"`
+			Summary
+		}{
+			Type:    "summary",
+			Summary: r.Summary,
+		})
+		return
+	}
+
+	content, _ := json.MarshalIndent(struct {
+		Events  []Event `json:"events"`
+		Summary Summary `json:"summary"`
+	}{
+		Events:  r.Events,
+		Summary: r.Summary,
+	}, "", "  ")
+
+	r.Writer.Write(append(content, '\n'))
+
+}
+
+// Close closes the report writer when it is a file
+func (r *Report) Close() error {
+
+	if file, ok := r.Writer.(*os.File); ok && file != os.Stdout {
+		return file.Close()
+	}
+
+	return nil
+}
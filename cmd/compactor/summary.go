@@ -0,0 +1,142 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/cli"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Totals struct
+type Totals struct {
+	Namespace  string
+	Packages   int
+	InputSize  int64
+	OutputSize int64
+	Duration   int64
+}
+
+// saved returns the percentage of size saved from input to output
+func saved(input int64, output int64) string {
+
+	if input == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f%%", float64(input-output)/float64(input)*100)
+}
+
+// alternatives returns the extensions of the generated files
+func alternatives(generated []string) string {
+
+	var list []string
+	for _, path := range generated {
+		extension := system.Extension(path)
+		if !slices.Contains(list, extension) {
+			list = append(list, extension)
+		}
+	}
+
+	if len(list) == 0 {
+		return "-"
+	}
+
+	return strings.Join(list, " ")
+}
+
+// printSummary prints the table of processed packages with sizes and savings
+// Packages are sorted by output size, followed by the totals per plugin namespace
+func printSummary(report *Report) {
+
+	var events []Event
+	var totals []*Totals
+
+	for _, event := range report.Events {
+		if event.Type == "success" {
+			events = append(events, event)
+		}
+	}
+
+	if len(events) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(events, func(a Event, b Event) int {
+		return cmp.Compare(b.OutputSize, a.OutputSize)
+	})
+
+	format := "%-40s %-12s %-12s %-8s %-16s %s\n"
+
+	cli.Printf(cli.Purple, "\n[SUMMARY] --- PACKAGES ---\n")
+	cli.Printf(cli.Notice, format, "FILE", "SOURCE", "OUTPUT", "SAVED", "GENERATED", "TIME")
+
+	for _, event := range events {
+
+		cli.Printf(
+			"",
+			format,
+			event.File,
+			system.FormatSize(event.InputSize),
+			system.FormatSize(event.OutputSize),
+			saved(event.InputSize, event.OutputSize),
+			alternatives(event.Generated),
+			fmt.Sprintf("%dms", event.Duration),
+		)
+
+		index := slices.IndexFunc(totals, func(item *Totals) bool {
+			return item.Namespace == event.Plugin
+		})
+
+		if index == -1 {
+			totals = append(totals, &Totals{Namespace: event.Plugin})
+			index = len(totals) - 1
+		}
+
+		totals[index].Packages++
+		totals[index].InputSize += event.InputSize
+		totals[index].OutputSize += event.OutputSize
+		totals[index].Duration += event.Duration
+
+	}
+
+	slices.SortStableFunc(totals, func(a *Totals, b *Totals) int {
+		return cmp.Compare(b.OutputSize, a.OutputSize)
+	})
+
+	cli.Printf(cli.Purple, "\n[SUMMARY] --- PLUGINS ---\n")
+	cli.Printf(cli.Notice, format, "PLUGIN", "SOURCE", "OUTPUT", "SAVED", "PACKAGES", "TIME")
+
+	var input, output int64
+	for _, total := range totals {
+
+		input += total.InputSize
+		output += total.OutputSize
+
+		cli.Printf(
+			"",
+			format,
+			total.Namespace,
+			system.FormatSize(total.InputSize),
+			system.FormatSize(total.OutputSize),
+			saved(total.InputSize, total.OutputSize),
+			fmt.Sprintf("%d", total.Packages),
+			fmt.Sprintf("%dms", total.Duration),
+		)
+
+	}
+
+	cli.Printf(
+		cli.Success,
+		format,
+		"TOTAL",
+		system.FormatSize(input),
+		system.FormatSize(output),
+		saved(input, output),
+		fmt.Sprintf("%d", len(events)),
+		"",
+	)
+
+}
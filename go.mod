@@ -2,6 +2,11 @@ module github.com/mateussouzaweb/compactor
 
 go 1.25.0
 
-require github.com/tdewolff/minify/v2 v2.24.13
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/cespare/xxhash/v2 v2.3.0
+	github.com/tdewolff/minify/v2 v2.24.13
+	golang.org/x/sys v0.43.0
+)
 
 require github.com/tdewolff/parse/v2 v2.8.13 // indirect
@@ -53,8 +53,14 @@ if ! /sbin/ldconfig -p | grep -q libjpeg; then
   apt install -y libjpeg-progs
 fi
 
+# Install imagemagick if missing
+if ! command -v convert >/dev/null 2>&1; then
+  echo "[INFO] Installing ImageMagick..."
+  apt install -y imagemagick
+fi
+
 # Install required npm packages globally
-PKGS=(gifsicle jpegoptim-bin cwebp-bin optipng-bin sass-embedded terser typescript svgo html-minifier rollup)
+PKGS=(gifsicle jpegoptim-bin cwebp-bin optipng-bin sass-embedded terser typescript rollup)
 INSTALLED=$(npm list -g)
 
 echo "[INFO] Checking NPM packages..."
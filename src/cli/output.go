@@ -2,9 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 )
 
+// Output writer
+// Machine-readable reports can move the text output to the standard error
+var Output io.Writer = os.Stdout
+
 // Colors
 var (
 	Reset   = "\033[0m"
@@ -27,11 +32,11 @@ func NoColor() bool {
 	return os.Getenv("NO_COLOR") != "" || os.Getenv("CLICOLOR") == "0"
 }
 
-// Printf displays a info to standard output
+// Printf displays a info to output
 func Printf(color string, format string, args ...any) {
 	if NoColor() {
-		fmt.Printf(format, args...)
+		fmt.Fprintf(Output, format, args...)
 	} else {
-		fmt.Printf(color+format+Reset, args...)
+		fmt.Fprintf(Output, color+format+Reset, args...)
 	}
 }
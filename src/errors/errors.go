@@ -10,3 +10,17 @@ func Join(errPtr *error, errFunc func() error) {
 		*errPtr = errors.Join(*errPtr, err)
 	}
 }
+
+// List retrieves the list of errors joined into the error
+func List(err error) []error {
+
+	if err == nil {
+		return nil
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+
+	return []error{err}
+}
@@ -7,10 +7,12 @@ import (
 )
 
 // Plugin return the compactor plugin instance
+// Shares the transpiler service of the sass plugin on the same builder
 func Plugin() *processor.Plugin {
 	return &processor.Plugin{
 		Namespace:  "css",
 		Extensions: []string{".css"},
+		Tools:      sass.Tools,
 		Init:       sass.Init,
 		Shutdown:   sass.Shutdown,
 		Resolve:    sass.Resolve,
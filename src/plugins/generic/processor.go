@@ -1,6 +1,8 @@
 package generic
 
 import (
+	"context"
+
 	"github.com/mateussouzaweb/compactor/src/processor"
 	"github.com/mateussouzaweb/compactor/src/system"
 )
@@ -15,9 +17,16 @@ func Shutdown(options *processor.Options) error {
 	return nil
 }
 
-// Resolve returns the clean file destination path for given source file
+// Resolve returns the file destination path for given source file
+// Destination has clean name, unless hash is explicitly enabled for the path
 func Resolve(options *processor.Options, file *processor.File) (string, error) {
+
 	destination := options.ToDestination(file.Path)
+
+	if options.ShouldHash(file.Path, false) {
+		destination = processor.HashDestination(options, file, destination)
+	}
+
 	return destination, nil
 }
 
@@ -28,13 +37,10 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 }
 
 // Transform creates generic copy of file(s) content to destination
-func Transform(options *processor.Options, file *processor.File) error {
-
-	content := file.Content
-	perm := file.Permission
-	destination := file.Destination
+// File permissions are kept on the copy
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
 
-	err := system.Write(destination, content, perm)
+	err := system.Copy(file.Path, file.Destination)
 	if err != nil {
 		return err
 	}
@@ -43,7 +49,7 @@ func Transform(options *processor.Options, file *processor.File) error {
 }
 
 // Optimize apply optimizations into the destination file
-func Optimize(options *processor.Options, file *processor.File) error {
+func Optimize(ctx context.Context, options *processor.Options, file *processor.File) error {
 	return nil
 }
 
@@ -1,13 +1,22 @@
 package gif
 
 import (
+	"context"
+
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
 	"github.com/mateussouzaweb/compactor/src/processor"
 	"github.com/mateussouzaweb/compactor/src/system"
 )
 
+// Gifsicle tool
+var Gifsicle = processor.Tool{
+	Name:    "gifsicle",
+	Version: []string{"--version"},
+	Install: "apt install gifsicle or npm install -g gifsicle",
+}
+
 // Transform processor
-func Transform(options *processor.Options, file *processor.File) error {
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	err := system.Copy(file.Path, file.Destination)
 	if err != nil {
@@ -18,14 +27,16 @@ func Transform(options *processor.Options, file *processor.File) error {
 }
 
 // Optimize processor
-func Optimize(options *processor.Options, file *processor.File) error {
+func Optimize(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	if !options.ShouldCompress(file.Path) {
 		return nil
 	}
 
-	_, err := system.Exec(
-		"gifsicle",
+	_, err := processor.ExecTool(
+		ctx,
+		options,
+		Gifsicle,
 		"-03",
 		file.Destination,
 		"-o", file.Destination,
@@ -43,6 +54,7 @@ func Plugin() *processor.Plugin {
 	return &processor.Plugin{
 		Namespace:  "gif",
 		Extensions: []string{".gif"},
+		Tools:      []processor.Tool{Gifsicle},
 		Init:       generic.Init,
 		Shutdown:   generic.Shutdown,
 		Resolve:    generic.Resolve,
@@ -0,0 +1,244 @@
+package html
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Elements struct
+type Elements struct {
+	Tags    map[string]bool
+	IDs     map[string]bool
+	Classes map[string]bool
+}
+
+// Block struct
+type Block struct {
+	Prelude string
+	Body    string
+}
+
+// PageElements returns the list of tags, IDs and classes used on the HTML content
+func PageElements(content string) *Elements {
+
+	elements := &Elements{
+		Tags:    make(map[string]bool),
+		IDs:     make(map[string]bool),
+		Classes: make(map[string]bool),
+	}
+
+	regex := regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9-]*)`)
+	for _, match := range regex.FindAllStringSubmatch(content, -1) {
+		elements.Tags[strings.ToLower(match[1])] = true
+	}
+
+	regex = regexp.MustCompile(`\sid=("([^"]*)"|'([^']*)')`)
+	for _, match := range regex.FindAllStringSubmatch(content, -1) {
+		elements.IDs[strings.Trim(match[1], `'"`)] = true
+	}
+
+	regex = regexp.MustCompile(`\sclass=("([^"]*)"|'([^']*)')`)
+	for _, match := range regex.FindAllStringSubmatch(content, -1) {
+		for _, class := range strings.Fields(strings.Trim(match[1], `'"`)) {
+			elements.Classes[class] = true
+		}
+	}
+
+	return elements
+}
+
+// MatchSelector return if the CSS selector can match the page elements
+// Pseudo classes and attribute selectors are ignored, so the result is always permissive
+func MatchSelector(selector string, elements *Elements) bool {
+
+	selector = regexp.MustCompile(`\[[^\]]*\]`).ReplaceAllString(selector, "")
+	selector = regexp.MustCompile(`::?[a-zA-Z-]+(\([^)]*\))?`).ReplaceAllString(selector, "")
+	parts := regexp.MustCompile(`[\s>+~]+`).Split(strings.TrimSpace(selector), -1)
+
+	for _, part := range parts {
+
+		if part == "" || part == "*" {
+			continue
+		}
+
+		tag := regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*`).FindString(part)
+		if tag != "" && !elements.Tags[strings.ToLower(tag)] {
+			return false
+		}
+
+		for _, match := range regexp.MustCompile(`#([\w-]+)`).FindAllStringSubmatch(part, -1) {
+			if !elements.IDs[match[1]] {
+				return false
+			}
+		}
+
+		for _, match := range regexp.MustCompile(`\.([\w-]+)`).FindAllStringSubmatch(part, -1) {
+			if !elements.Classes[match[1]] {
+				return false
+			}
+		}
+
+	}
+
+	return true
+}
+
+// SplitBlocks splits the CSS content into top level blocks
+// Statements without blocks, like @import and @charset, are ignored
+func SplitBlocks(content string) []Block {
+
+	var blocks []Block
+	var quote byte
+
+	content = regexp.MustCompile(`(?s)/\*.*?\*/`).ReplaceAllString(content, "")
+	depth := 0
+	start := 0
+	open := 0
+
+	for i := 0; i < len(content); i++ {
+
+		char := content[i]
+
+		if quote != 0 {
+			if char == '\\' {
+				i++
+			} else if char == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch char {
+		case '"', '\'':
+			quote = char
+		case '{':
+			if depth == 0 {
+				open = i
+			}
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				blocks = append(blocks, Block{
+					Prelude: strings.TrimSpace(content[start:open]),
+					Body:    content[open+1 : i],
+				})
+				start = i + 1
+			}
+		case ';':
+			if depth == 0 {
+				start = i + 1
+			}
+		}
+
+	}
+
+	return blocks
+}
+
+// CriticalCSS extracts the CSS rules that matches the page elements
+func CriticalCSS(content string, elements *Elements) string {
+
+	var result []string
+
+	for _, block := range SplitBlocks(content) {
+
+		prelude := block.Prelude
+
+		switch {
+		case strings.HasPrefix(prelude, "@media"),
+			strings.HasPrefix(prelude, "@supports"),
+			strings.HasPrefix(prelude, "@layer"):
+			inner := CriticalCSS(block.Body, elements)
+			if inner != "" {
+				result = append(result, prelude+"{"+inner+"}")
+			}
+		case strings.HasPrefix(prelude, "@font-face"):
+			result = append(result, prelude+"{"+block.Body+"}")
+		case strings.HasPrefix(prelude, "@"):
+			continue
+		default:
+			var selectors []string
+			for _, selector := range strings.Split(prelude, ",") {
+				if MatchSelector(selector, elements) {
+					selectors = append(selectors, strings.TrimSpace(selector))
+				}
+			}
+			if len(selectors) > 0 {
+				result = append(result, strings.Join(selectors, ",")+"{"+block.Body+"}")
+			}
+		}
+
+	}
+
+	return strings.Join(result, "")
+}
+
+// RebaseURLs rewrites the relative url() references of the CSS from the stylesheet folder to the page folder
+// Needed when CSS is moved into the page, because references are relative to the file that contains them
+func RebaseURLs(content string, stylesheet string, page string) string {
+
+	regex := regexp.MustCompile(`url\(\s*("([^"]+)"|'([^']+)'|([^'")]+))\s*\)`)
+	absolute := regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*:|/|#)`)
+
+	return regex.ReplaceAllStringFunc(content, func(match string) string {
+
+		parts := regex.FindStringSubmatch(match)
+		url := strings.TrimSpace(parts[2] + parts[3] + parts[4])
+
+		if url == "" || absolute.MatchString(url) {
+			return match
+		}
+
+		suffix := ""
+		if index := strings.IndexAny(url, "?#"); index != -1 {
+			suffix = url[index:]
+			url = url[:index]
+		}
+
+		path := filepath.Join(system.Dir(stylesheet), url)
+		rebased := filepath.ToSlash(system.Relative(system.Dir(page), path))
+
+		return `url("` + rebased + suffix + `")`
+	})
+}
+
+// AsyncStylesheet converts the stylesheet link into a non-blocking load pattern
+func AsyncStylesheet(html string) string {
+
+	regex := regexp.MustCompile(`rel=("[^"]*"|'[^']*')`)
+	preload := regex.ReplaceAllLiteralString(html, `rel="preload"`)
+	preload = AddAttribute(preload, "as", "style")
+	preload = AddAttribute(preload, "onload", "this.onload=null;this.rel='stylesheet'")
+
+	return preload + "<noscript>" + html + "</noscript>"
+}
+
+// AddCritical inlines the critical CSS of the stylesheet and load the full stylesheet without blocking
+// Stylesheet should be processed before, otherwise the code is kept unchanged
+func AddCritical(html string, file *processor.File, related *processor.File, elements *Elements) string {
+
+	if ExtractAttribute(html, "rel", "") != "stylesheet" {
+		return html
+	}
+	if system.Extension(related.Destination) != ".css" {
+		return html
+	}
+
+	content, err := system.Read(related.Destination)
+	if err != nil {
+		return html
+	}
+
+	critical := CriticalCSS(content, elements)
+	critical = RebaseURLs(critical, related.Destination, file.Destination)
+	if critical == "" {
+		return AsyncStylesheet(html)
+	}
+
+	return "<style>" + critical + "</style>" + AsyncStylesheet(html)
+}
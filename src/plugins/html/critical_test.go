@@ -0,0 +1,57 @@
+package html
+
+import "testing"
+
+func TestCriticalCSS(t *testing.T) {
+
+	elements := PageElements(`<body><main id="app" class="page dark"><h1>Title</h1></main></body>`)
+
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{`h1{color:red}h2{color:blue}`, `h1{color:red}`},
+		{`.page{margin:0}.other{margin:1px}`, `.page{margin:0}`},
+		{`#app,#missing{padding:0}`, `#app{padding:0}`},
+		{`main .dark h1:hover{color:red}`, `main .dark h1:hover{color:red}`},
+		{`@media (min-width:10px){h1{color:red}p{color:blue}}`, `@media (min-width:10px){h1{color:red}}`},
+		{`@media print{p{color:blue}}`, ``},
+		{`@font-face{font-family:x}`, `@font-face{font-family:x}`},
+		{`@import url("x.css");h1{color:red}`, `h1{color:red}`},
+		{`/* h1{} */ body{color:"}"}`, `body{color:"}"}`},
+	}
+
+	for _, test := range tests {
+		result := CriticalCSS(test.content, elements)
+		if result != test.expected {
+			t.Errorf("CriticalCSS(%q) = %q, expected %q", test.content, result, test.expected)
+		}
+	}
+
+}
+
+func TestRebaseURLs(t *testing.T) {
+
+	tests := []struct {
+		content    string
+		stylesheet string
+		page       string
+		expected   string
+	}{
+		{`a{background:url("../img/a.png")}`, "/dist/css/app.css", "/dist/index.html", `a{background:url("img/a.png")}`},
+		{`a{background:url(../img/a.png)}`, "/dist/css/app.css", "/dist/pages/about.html", `a{background:url("../img/a.png")}`},
+		{`a{background:url('b.png?v=1#x')}`, "/dist/css/app.css", "/dist/index.html", `a{background:url("css/b.png?v=1#x")}`},
+		{`a{background:url("/img/a.png")}`, "/dist/css/app.css", "/dist/index.html", `a{background:url("/img/a.png")}`},
+		{`a{background:url(data:image/png;base64,AA)}`, "/dist/css/app.css", "/dist/index.html", `a{background:url(data:image/png;base64,AA)}`},
+		{`a{background:url(https://example.com/a.png)}`, "/dist/css/app.css", "/dist/index.html", `a{background:url(https://example.com/a.png)}`},
+		{`a{filter:url(#svg)}`, "/dist/css/app.css", "/dist/index.html", `a{filter:url(#svg)}`},
+	}
+
+	for _, test := range tests {
+		result := RebaseURLs(test.content, test.stylesheet, test.page)
+		if result != test.expected {
+			t.Errorf("RebaseURLs(%q) = %q, expected %q", test.content, result, test.expected)
+		}
+	}
+
+}
@@ -0,0 +1,199 @@
+package html
+
+import (
+	stdhtml "html"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// InlineHashes returns the CSP hashes of every inline block of the given tag
+func InlineHashes(content string, tag string) []string {
+
+	var hashes []string
+
+	regex := regexp.MustCompile(`(?is)<` + tag + `(\s[^>]*)?>(.*?)</` + tag + `>`)
+	matches := regex.FindAllStringSubmatch(content, -1)
+
+	for _, match := range matches {
+
+		// Ignore external resources and empty blocks
+		if ExtractAttribute(match[1], "src", "") != "" || match[2] == "" {
+			continue
+		}
+
+		hash := "'" + system.Integrity(match[2], "sha256") + "'"
+		if !slices.Contains(hashes, hash) {
+			hashes = append(hashes, hash)
+		}
+
+	}
+
+	return hashes
+}
+
+// HandlerHashes returns the CSP hashes of every inline event handler attribute, like onload
+// Browsers only allow hashed handlers when the policy also has the 'unsafe-hashes' source
+func HandlerHashes(content string) []string {
+
+	var hashes []string
+
+	regex := regexp.MustCompile(`(?i)<[a-z][^>]*?\son[a-z]+=("([^"]*)"|'([^']*)')`)
+	attribute := regexp.MustCompile(`(?i)\son[a-z]+=("([^"]*)"|'([^']*)')`)
+
+	for _, tag := range regex.FindAllString(content, -1) {
+		for _, match := range attribute.FindAllStringSubmatch(tag, -1) {
+
+			code := stdhtml.UnescapeString(match[2] + match[3])
+			if code == "" {
+				continue
+			}
+
+			hash := "'" + system.Integrity(code, "sha256") + "'"
+			if !slices.Contains(hashes, hash) {
+				hashes = append(hashes, hash)
+			}
+
+		}
+	}
+
+	return hashes
+}
+
+// MergePolicy appends the sources into the directive of the policy
+func MergePolicy(policy string, directive string, sources []string) string {
+
+	if len(sources) == 0 {
+		return policy
+	}
+
+	var result []string
+	found := false
+
+	for _, item := range strings.Split(policy, ";") {
+
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		fields := strings.Fields(item)
+		if fields[0] == directive {
+			found = true
+			for _, source := range sources {
+				if !slices.Contains(fields, source) {
+					fields = append(fields, source)
+				}
+			}
+		}
+
+		result = append(result, strings.Join(fields, " "))
+
+	}
+
+	if !found {
+		fields := append([]string{directive, "'self'"}, sources...)
+		result = append(result, strings.Join(fields, " "))
+	}
+
+	return strings.Join(result, "; ")
+}
+
+// ContentPolicyRelated returns the possible headers file generated with the content policy
+func ContentPolicyRelated(options *processor.Options, file *processor.File) []processor.Related {
+
+	var related []processor.Related
+
+	if !options.ShouldGenerateContentPolicy(file.Path) || options.ContentPolicy.Output != "headers" {
+		return related
+	}
+
+	// Destination follows the final page destination, being updated on transform
+	destination := ""
+	if file.Destination != "" {
+		destination = file.Destination + ".headers"
+	}
+
+	related = append(related, processor.Related{
+		Type:       "headers",
+		Dependency: true,
+		Source:     "",
+		Path:       file.File + ".headers",
+		File: &processor.File{
+			Path:        file.Path + ".headers",
+			Destination: destination,
+			Root:        file.Root,
+			Location:    file.Location + ".headers",
+			Folder:      file.Folder,
+			File:        file.File + ".headers",
+			Name:        file.File,
+			Extension:   ".headers",
+			Permission:  file.Permission,
+		},
+	})
+
+	return related
+}
+
+// AddContentPolicy generates the content security policy with hashes of inline scripts and styles
+// Policy is merged into existing meta tag, injected as new meta tag or written to the page headers file
+func AddContentPolicy(options *processor.Options, file *processor.File, content string) (string, error) {
+
+	scripts := InlineHashes(content, "script")
+	styles := InlineHashes(content, "style")
+
+	// Event handlers, like the one of async stylesheets, require unsafe hashes
+	handlers := HandlerHashes(content)
+	if len(handlers) > 0 {
+		scripts = append(scripts, "'unsafe-hashes'")
+		scripts = append(scripts, handlers...)
+	}
+
+	regex := regexp.MustCompile(`(?i)<meta[^>]*http-equiv=["']Content-Security-Policy["'][^>]*>`)
+	meta := regex.FindString(content)
+	policy := ExtractAttribute(meta, "content", "")
+
+	policy = MergePolicy(policy, "script-src", scripts)
+	policy = MergePolicy(policy, "style-src", styles)
+
+	// Headers file is named after the page destination
+	headers := file.Destination + ".headers"
+	for _, related := range file.Related {
+		if related.Type == "headers" {
+			related.File.Destination = headers
+		}
+	}
+
+	// Without policy, headers file from previous builds is stale
+	if policy == "" {
+		if system.Exist(headers) {
+			return content, system.Delete(headers)
+		}
+		return content, nil
+	}
+
+	if meta != "" {
+		code := regexp.MustCompile(`content=("[^"]*"|'[^']*')`).ReplaceAllLiteralString(
+			meta, `content="`+policy+`"`,
+		)
+		content = strings.Replace(content, meta, code, 1)
+	} else if options.ContentPolicy.Output != "headers" {
+		code := `<meta http-equiv="Content-Security-Policy" content="` + policy + `">`
+		head := regexp.MustCompile(`(?i)<head(\s[^>]*)?>`).FindString(content)
+		if head != "" {
+			content = strings.Replace(content, head, head+code, 1)
+		}
+	}
+
+	if options.ContentPolicy.Output == "headers" {
+		err := system.Write(headers, "Content-Security-Policy: "+policy+"\n", file.Permission)
+		if err != nil {
+			return content, err
+		}
+	}
+
+	return content, nil
+}
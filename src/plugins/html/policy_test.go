@@ -0,0 +1,104 @@
+package html
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+func TestInlineHashes(t *testing.T) {
+
+	content := `<script>a()</script><script src="app.js"></script><script type="module">a()</script><script></script><style>p{}</style>`
+
+	scripts := InlineHashes(content, "script")
+	expected := []string{"'" + system.Integrity("a()", "sha256") + "'"}
+	if !slices.Equal(scripts, expected) {
+		t.Errorf("InlineHashes(script) = %v, expected %v", scripts, expected)
+	}
+
+	styles := InlineHashes(content, "style")
+	expected = []string{"'" + system.Integrity("p{}", "sha256") + "'"}
+	if !slices.Equal(styles, expected) {
+		t.Errorf("InlineHashes(style) = %v, expected %v", styles, expected)
+	}
+
+}
+
+func TestMergePolicy(t *testing.T) {
+
+	tests := []struct {
+		policy    string
+		directive string
+		sources   []string
+		expected  string
+	}{
+		{"", "script-src", []string{"'a'"}, "script-src 'self' 'a'"},
+		{"default-src 'self'", "script-src", []string{"'a'"}, "default-src 'self'; script-src 'self' 'a'"},
+		{"script-src 'self' 'a';  img-src *", "script-src", []string{"'a'", "'b'"}, "script-src 'self' 'a' 'b'; img-src *"},
+		{"script-src 'none'", "script-src", []string{}, "script-src 'none'"},
+	}
+
+	for _, test := range tests {
+		result := MergePolicy(test.policy, test.directive, test.sources)
+		if result != test.expected {
+			t.Errorf("MergePolicy(%q, %q, %v) = %q, expected %q", test.policy, test.directive, test.sources, result, test.expected)
+		}
+	}
+
+}
+
+func TestHandlerHashes(t *testing.T) {
+
+	handler := "this.onload=null;this.rel='stylesheet'"
+	content := AsyncStylesheet(`<link rel="stylesheet" href="app.css">`) + `<a href="#" onclick='go("x")'>x</a><p>onload="ignored"</p>`
+
+	hashes := HandlerHashes(content)
+	expected := []string{
+		"'" + system.Integrity(handler, "sha256") + "'",
+		"'" + system.Integrity(`go("x")`, "sha256") + "'",
+	}
+
+	if !slices.Equal(hashes, expected) {
+		t.Errorf("HandlerHashes() = %v, expected %v", hashes, expected)
+	}
+
+}
+
+func TestAddContentPolicyHeaders(t *testing.T) {
+
+	options := &processor.Options{
+		ContentPolicy: processor.ContentPolicy{Enabled: true, Output: "headers"},
+	}
+
+	destination := filepath.Join(t.TempDir(), "index.abc123.html")
+	file := &processor.File{
+		Path:        "/src/index.html",
+		File:        "index.html",
+		Destination: destination,
+		Permission:  0644,
+	}
+	file.Related = ContentPolicyRelated(options, file)
+
+	_, err := AddContentPolicy(options, file, "<script>a()</script>")
+	if err != nil {
+		t.Fatalf("AddContentPolicy() error = %v", err)
+	}
+	if !system.Exist(destination + ".headers") {
+		t.Errorf("AddContentPolicy() did not write %s.headers", destination)
+	}
+	if len(file.Related) != 1 || file.Related[0].File.Destination != destination+".headers" {
+		t.Errorf("AddContentPolicy() related = %v, expected destination %s.headers", file.Related, destination)
+	}
+
+	_, err = AddContentPolicy(options, file, "<p>a</p>")
+	if err != nil {
+		t.Fatalf("AddContentPolicy() error = %v", err)
+	}
+	if system.Exist(destination + ".headers") {
+		t.Errorf("AddContentPolicy() kept %s.headers without policy", destination)
+	}
+
+}
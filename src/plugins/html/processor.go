@@ -1,12 +1,19 @@
 package html
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
 	"github.com/mateussouzaweb/compactor/src/processor"
 	"github.com/mateussouzaweb/compactor/src/system"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/html"
+	"github.com/tdewolff/minify/v2/js"
+	"github.com/tdewolff/minify/v2/json"
+	"github.com/tdewolff/minify/v2/svg"
 )
 
 // ExtractAttribute find the value of the attribute
@@ -22,6 +29,146 @@ func ExtractAttribute(html string, attribute string, defaultValue string) string
 	return defaultValue
 }
 
+// AddAttribute appends the attribute into the opening tag of the HTML code if not present yet
+func AddAttribute(html string, attribute string, value string) string {
+
+	if ExtractAttribute(html, attribute, "") != "" {
+		return html
+	}
+
+	index := strings.Index(html, ">")
+	if index == -1 {
+		return html
+	}
+	if html[index-1] == '/' {
+		index--
+	}
+
+	start := strings.TrimRight(html[:index], " ")
+	end := html[index:]
+
+	return start + " " + attribute + `="` + value + `"` + end
+}
+
+// RemoveAttribute removes the attribute from the HTML code
+func RemoveAttribute(html string, attribute string) string {
+
+	regex := regexp.MustCompile(`\s` + attribute + `(=("[^"]*"|'[^']*'))?`)
+	html = regex.ReplaceAllString(html, "")
+
+	return html
+}
+
+// AddInline replaces the HTML code with the inlined content of the related file when possible
+// Images are embedded as data URIs, while scripts and stylesheets are converted to inline tags
+// Deferred, async and module scripts are kept external, because inline scripts run immediately
+func AddInline(options *processor.Options, html string, file *processor.File, related processor.Related) (string, bool) {
+
+	tag := TagName(html)
+	rel := ExtractAttribute(html, "rel", "")
+
+	if tag == "link" && rel != "stylesheet" {
+		return html, false
+	}
+	if tag == "script" && !CanInlineScript(html) {
+		return html, false
+	}
+
+	force := processor.ShouldForceInline(related.Path)
+	force = force || regexp.MustCompile(`\sdata-inline(\s|=|>|/)`).MatchString(html)
+
+	content, ok := processor.InlineContent(options, related.File, force)
+	if !ok {
+		return html, false
+	}
+
+	switch tag {
+	case "img":
+		dataURI := system.DataURI(content, related.File.Destination)
+		html = strings.Replace(html, related.Path, dataURI, 1)
+		html = RemoveAttribute(html, "data-inline")
+		return html, true
+	case "script":
+		content = strings.ReplaceAll(content, "</script", "<\\/script")
+		code := "<script"
+		if kind := ExtractAttribute(html, "type", ""); kind != "" {
+			code += ` type="` + kind + `"`
+		}
+		return code + ">" + content + "</script>", true
+	case "link":
+		content = RebaseURLs(content, related.File.Destination, file.Destination)
+		code := "<style"
+		if media := ExtractAttribute(html, "media", ""); media != "" {
+			code += ` media="` + media + `"`
+		}
+		return code + ">" + content + "</style>", true
+	}
+
+	return html, false
+}
+
+// TagName retrieves the lowercase name of the opening tag of the HTML code
+func TagName(html string) string {
+
+	match := regexp.MustCompile(`^<([a-zA-Z]+)`).FindStringSubmatch(html)
+	if match == nil {
+		return ""
+	}
+
+	return strings.ToLower(match[1])
+}
+
+// SupportsIntegrity return if browsers check subresource integrity on the HTML tag
+// Only scripts with source and stylesheet, preload or modulepreload links are supported
+func SupportsIntegrity(html string) bool {
+
+	switch TagName(html) {
+	case "script":
+		return ExtractAttribute(html, "src", "") != ""
+	case "link":
+		for _, rel := range strings.Fields(strings.ToLower(ExtractAttribute(html, "rel", ""))) {
+			if rel == "stylesheet" || rel == "preload" || rel == "modulepreload" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// CanInlineScript return if the script tag runs at the same point when inlined
+// Deferred, async and module scripts have different execution order than inline scripts
+func CanInlineScript(html string) bool {
+
+	if regexp.MustCompile(`(?i)\s(defer|async)(\s|=|>|/)`).MatchString(html) {
+		return false
+	}
+
+	return strings.ToLower(ExtractAttribute(html, "type", "")) != "module"
+}
+
+// AddIntegrity appends the subresource integrity attributes from the final related file output
+// Related file should be processed before, otherwise the code is kept unchanged
+func AddIntegrity(options *processor.Options, html string, related *processor.File) string {
+
+	if !SupportsIntegrity(html) {
+		return html
+	}
+
+	content, err := system.Read(related.Destination)
+	if err != nil {
+		return html
+	}
+
+	html = AddAttribute(html, "integrity", system.Integrity(content, "sha384"))
+
+	if options.Integrity.CrossOrigin != "" {
+		html = AddAttribute(html, "crossorigin", options.Integrity.CrossOrigin)
+	}
+
+	return html
+}
+
 // Related processor
 func Related(options *processor.Options, file *processor.File) ([]processor.Related, error) {
 
@@ -29,7 +176,7 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 
 	// Detect imports
 	regex := regexp.MustCompile(`<!-- @import ?("(.+)"|'(.+)') -->`)
-	matches := regex.FindAllStringSubmatch(file.Content, -1)
+	matches := regex.FindAllStringSubmatch(file.Content(), -1)
 	extensions := []string{".html", ".htm"}
 
 	for _, match := range matches {
@@ -37,20 +184,20 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 		path := strings.Trim(match[1], `'"`)
 		filePath := system.Resolve(path, extensions, system.Dir(file.Path))
 
-		if processor.GetFile(filePath).Path != "" {
+		if options.Builder().GetFile(filePath).Path != "" {
 			related = append(related, processor.Related{
 				Type:       "partial",
 				Dependency: true,
 				Source:     source,
 				Path:       path,
-				File:       processor.GetFile(filePath),
+				File:       options.Builder().GetFile(filePath),
 			})
 		}
 	}
 
 	// Detect scripts
 	regex = regexp.MustCompile(`<script(.+)?>(.+)?</script>`)
-	matches = regex.FindAllStringSubmatch(file.Content, -1)
+	matches = regex.FindAllStringSubmatch(file.Content(), -1)
 	extensions = []string{".js", ".mjs", ".jsx", ".ts", ".mts", ".tsx"}
 
 	for _, match := range matches {
@@ -68,15 +215,16 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 			continue
 		}
 
-		filePath := system.Resolve(src, extensions, system.Dir(file.Path))
+		clean := strings.SplitN(src, "?", 2)[0]
+		filePath := system.Resolve(clean, extensions, system.Dir(file.Path))
 
-		if processor.GetFile(filePath).Path != "" {
+		if options.Builder().GetFile(filePath).Path != "" {
 			related = append(related, processor.Related{
 				Type:       "other",
 				Dependency: false,
 				Source:     code,
 				Path:       src,
-				File:       processor.GetFile(filePath),
+				File:       options.Builder().GetFile(filePath),
 			})
 		}
 
@@ -84,7 +232,7 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 
 	// Detect links
 	regex = regexp.MustCompile(`<link(.+)?\/?>`)
-	matches = regex.FindAllStringSubmatch(file.Content, -1)
+	matches = regex.FindAllStringSubmatch(file.Content(), -1)
 
 	for _, match := range matches {
 
@@ -100,55 +248,78 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 			continue
 		}
 
-		filePath := system.Resolve(href, []string{}, system.Dir(file.Path))
+		clean := strings.SplitN(href, "?", 2)[0]
+		filePath := system.Resolve(clean, []string{}, system.Dir(file.Path))
 
-		if processor.GetFile(filePath).Path != "" {
+		if options.Builder().GetFile(filePath).Path != "" {
 			related = append(related, processor.Related{
 				Type:       "other",
 				Dependency: false,
 				Source:     code,
 				Path:       href,
-				File:       processor.GetFile(filePath),
+				File:       options.Builder().GetFile(filePath),
 			})
 		}
 
 	}
 
-	return related, nil
-}
+	// Detect images
+	regex = regexp.MustCompile(`<img\s[^>]*>`)
+	matches = regex.FindAllStringSubmatch(file.Content(), -1)
 
-// Minify HTML content
-func Minify(content string) (string, error) {
+	for _, match := range matches {
+
+		code := match[0]
+		src := ExtractAttribute(code, "src", "")
 
-	file := system.TemporaryFile("minify.html")
-	defer system.Delete(file)
+		// Ignore protocol and data images, only handle relative and absolute paths
+		if src == "" || strings.Contains(src, ":") {
+			continue
+		}
 
-	err := system.Write(file, content, 0775)
-	if err != nil {
-		return content, err
-	}
-
-	_, err = system.Exec(
-		"html-minifier",
-		"--output", file,
-		"--collapse-whitespace",
-		"--conservative-collapse",
-		"--remove-comments",
-		"--remove-script-type-attributes",
-		"--remove-style-link-type-attributes",
-		"--use-short-doctype",
-		"--minify-urls", "true",
-		"--minify-css", "true",
-		"--minify-js", "true",
-		"--ignore-custom-fragments", "/{{[{]?(.*?)[}]?}}/",
-		file,
-	)
+		clean := strings.SplitN(src, "?", 2)[0]
+		filePath := system.Resolve(clean, []string{}, system.Dir(file.Path))
+
+		if options.Builder().GetFile(filePath).Path != "" {
+			related = append(related, processor.Related{
+				Type:       "other",
+				Dependency: false,
+				Source:     code,
+				Path:       src,
+				File:       options.Builder().GetFile(filePath),
+			})
+		}
 
-	if err != nil {
-		return content, err
 	}
 
-	content, err = system.Read(file)
+	// Add possible content policy headers file
+	related = append(related, ContentPolicyRelated(options, file)...)
+
+	return related, nil
+}
+
+// Minify HTML content
+// Whitespaces are conservatively collapsed and template fragments are ignored
+func Minify(content string) (string, error) {
+
+	m := minify.New()
+	m.AddFunc("text/css", css.Minify)
+	m.AddFuncRegexp(regexp.MustCompile("^(application|text)/(x-)?(java|ecma)script$"), js.Minify)
+	m.AddFunc("importmap", json.Minify)
+	m.AddFuncRegexp(regexp.MustCompile("[/+]json$"), json.Minify)
+	m.AddFunc("image/svg+xml", svg.Minify)
+	m.Add("generic", &html.Minifier{
+		KeepComments:        false,
+		KeepSpecialComments: true,
+		KeepDefaultAttrVals: false,
+		KeepDocumentTags:    true,
+		KeepEndTags:         true,
+		KeepQuotes:          true,
+		KeepWhitespace:      true,
+		TemplateDelims:      html.GoTemplateDelims,
+	})
+
+	content, err := m.String("generic", content)
 
 	return content, err
 }
@@ -160,7 +331,7 @@ func MergeContent(file *processor.File) string {
 		return ""
 	}
 
-	content := file.Content
+	content := file.Content()
 
 	for _, related := range file.Related {
 		if related.Type == "partial" && related.File.Exists {
@@ -180,9 +351,11 @@ func MergeContent(file *processor.File) string {
 }
 
 // Transform processor
-func Transform(options *processor.Options, file *processor.File) error {
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	content := MergeContent(file)
+	critical := options.ShouldInlineCritical(file.Path)
+	elements := PageElements(content)
 
 	for _, related := range file.Related {
 
@@ -192,6 +365,7 @@ func Transform(options *processor.Options, file *processor.File) error {
 
 		path := related.Path
 		destination := options.CleanPath(related.File.Destination)
+		destination = options.ToVersioned(destination, related.File.Hash)
 
 		if strings.HasPrefix(path, "/") {
 			destination = "/" + destination
@@ -199,7 +373,22 @@ func Transform(options *processor.Options, file *processor.File) error {
 			destination = "./" + destination
 		}
 
-		content = strings.Replace(content, path, destination, 1)
+		code, inlined := AddInline(options, related.Source, file, related)
+		if inlined {
+			content = strings.Replace(content, related.Source, code, 1)
+			continue
+		}
+
+		code = strings.Replace(related.Source, path, destination, 1)
+
+		if options.ShouldGenerateIntegrity(related.File.Path) {
+			code = AddIntegrity(options, code, related.File)
+		}
+		if critical {
+			code = AddCritical(code, file, related.File, elements)
+		}
+
+		content = strings.Replace(content, related.Source, code, 1)
 
 	}
 
@@ -215,9 +404,12 @@ func Transform(options *processor.Options, file *processor.File) error {
 }
 
 // Optimize processor
-func Optimize(options *processor.Options, file *processor.File) error {
+func Optimize(ctx context.Context, options *processor.Options, file *processor.File) error {
+
+	compress := options.ShouldCompress(file.Path)
+	policy := options.ShouldGenerateContentPolicy(file.Path)
 
-	if !options.ShouldCompress(file.Path) {
+	if !compress && !policy {
 		return nil
 	}
 
@@ -226,9 +418,19 @@ func Optimize(options *processor.Options, file *processor.File) error {
 		return err
 	}
 
-	content, err = Minify(content)
-	if err != nil {
-		return err
+	if compress {
+		content, err = Minify(content)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Hashes are generated from the final inline code
+	if policy {
+		content, err = AddContentPolicy(options, file, content)
+		if err != nil {
+			return err
+		}
 	}
 
 	perm := file.Permission
@@ -0,0 +1,53 @@
+package html
+
+import "testing"
+
+func TestSupportsIntegrity(t *testing.T) {
+
+	tests := []struct {
+		html     string
+		expected bool
+	}{
+		{`<script src="app.js"></script>`, true},
+		{`<script>console.log(1)</script>`, false},
+		{`<link rel="stylesheet" href="app.css">`, true},
+		{`<link rel="preload" href="font.woff2" as="font">`, true},
+		{`<link rel="modulepreload" href="app.js">`, true},
+		{`<link rel="icon" href="favicon.ico">`, false},
+		{`<link rel="apple-touch-icon" href="icon.png">`, false},
+		{`<img src="image.png">`, false},
+	}
+
+	for _, test := range tests {
+		result := SupportsIntegrity(test.html)
+		if result != test.expected {
+			t.Errorf("SupportsIntegrity(%q) = %v, expected %v", test.html, result, test.expected)
+		}
+	}
+
+}
+
+func TestCanInlineScript(t *testing.T) {
+
+	tests := []struct {
+		html     string
+		expected bool
+	}{
+		{`<script src="app.js"></script>`, true},
+		{`<script type="text/javascript" src="app.js"></script>`, true},
+		{`<script defer src="app.js"></script>`, false},
+		{`<script src="app.js" defer></script>`, false},
+		{`<script async="async" src="app.js"></script>`, false},
+		{`<script type="module" src="app.js"></script>`, false},
+		{`<script src="deferred.js"></script>`, true},
+		{`<script data-async-loader src="app.js"></script>`, true},
+	}
+
+	for _, test := range tests {
+		result := CanInlineScript(test.html)
+		if result != test.expected {
+			t.Errorf("CanInlineScript(%q) = %v, expected %v", test.html, result, test.expected)
+		}
+	}
+
+}
@@ -0,0 +1,244 @@
+package ico
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mateussouzaweb/compactor/src/plugins/generic"
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Icon struct
+type Icon struct {
+	File string
+	Size int
+}
+
+// Icons list generated from the favicon source image
+var Icons = []Icon{
+	{File: "favicon-16x16.png", Size: 16},
+	{File: "favicon-32x32.png", Size: 32},
+	{File: "apple-touch-icon.png", Size: 180},
+	{File: "android-chrome-192x192.png", Size: 192},
+	{File: "android-chrome-512x512.png", Size: 512},
+}
+
+// Favicon file name and sizes included in the multi-resolution ICO file
+var Favicon = "favicon.ico"
+var FaviconSizes = "16,32,48"
+
+// Manifest file name
+var Manifest = "site.webmanifest"
+
+// IsFavicon return if the given file is a favicon source image
+func IsFavicon(file *processor.File) bool {
+	return file.Name == "favicon" && (file.Extension == ".svg" || file.Extension == ".png")
+}
+
+// generated returns the file reference of a generated output for the favicon
+func generated(options *processor.Options, file *processor.File, name string) *processor.File {
+
+	path := filepath.Join(system.Dir(file.Path), name)
+	found := options.Builder().GetFile(path)
+
+	if found.Path != "" {
+		return found
+	}
+
+	return &processor.File{
+		Path:        path,
+		Destination: options.ToDestination(path),
+		Root:        file.Root,
+		Location:    system.Clean(path, file.Root),
+		Folder:      file.Folder,
+		File:        name,
+		Name:        system.Name(name),
+		Extension:   system.Extension(name),
+		Permission:  file.Permission,
+	}
+}
+
+// FaviconRelated returns the list of generated files from the favicon source image
+func FaviconRelated(options *processor.Options, file *processor.File) []processor.Related {
+
+	var related []processor.Related
+
+	if !IsFavicon(file) {
+		return related
+	}
+
+	// Favicon and icons are always generated from the source image
+	names := []string{Favicon}
+	for _, icon := range Icons {
+		names = append(names, icon.File)
+	}
+
+	for _, name := range names {
+		related = append(related, processor.Related{
+			Type:       "icon",
+			Dependency: true,
+			Source:     "",
+			Path:       name,
+			File:       generated(options, file, name),
+		})
+	}
+
+	// Manifest can exist on source, being used as base for the final file
+	related = append(related, processor.Related{
+		Type:       "manifest",
+		Dependency: true,
+		Source:     "",
+		Path:       Manifest,
+		File:       generated(options, file, Manifest),
+	})
+
+	return related
+}
+
+// Convert tool
+// Only required when favicons or icons should be generated
+var Convert = processor.Tool{
+	Name:     "convert",
+	Optional: true,
+	Version:  []string{"-version"},
+	Install:  "apt install imagemagick",
+}
+
+// CreateIcon make a PNG copy of the image on the given size
+func CreateIcon(ctx context.Context, options *processor.Options, source string, destination string, size int) error {
+
+	_, err := processor.ExecTool(
+		ctx,
+		options,
+		Convert,
+		"-background", "none",
+		"-density", "384",
+		source,
+		"-resize", fmt.Sprintf("%dx%d", size, size),
+		destination,
+	)
+
+	return err
+}
+
+// CreateFavicon make a multi-resolution ICO file from the image
+func CreateFavicon(ctx context.Context, options *processor.Options, source string, destination string, sizes string) error {
+
+	_, err := processor.ExecTool(
+		ctx,
+		options,
+		Convert,
+		"-background", "none",
+		"-density", "384",
+		source,
+		"-define", "icon:auto-resize="+sizes,
+		destination,
+	)
+
+	return err
+}
+
+// CreateManifest writes the web app manifest with the generated icons
+func CreateManifest(options *processor.Options, file *processor.File, destination string) error {
+
+	manifest := make(map[string]any)
+	base := generated(options, file, Manifest)
+
+	if content := base.Content(); base.Exists && content != "" {
+		err := json.Unmarshal([]byte(content), &manifest)
+		if err != nil {
+			return err
+		}
+	}
+
+	var icons []map[string]string
+	for _, icon := range Icons {
+		if icon.Size < 192 {
+			continue
+		}
+
+		icons = append(icons, map[string]string{
+			"src":   icon.File,
+			"sizes": fmt.Sprintf("%dx%d", icon.Size, icon.Size),
+			"type":  "image/png",
+		})
+	}
+
+	manifest["icons"] = icons
+
+	var content []byte
+	var err error
+
+	if options.ShouldCompress(file.Path) {
+		content, err = json.Marshal(manifest)
+	} else {
+		content, err = json.MarshalIndent(manifest, "", "  ")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return system.Write(destination, string(content), file.Permission)
+}
+
+// Generate creates the favicon, icons and manifest from the favicon source image
+func Generate(ctx context.Context, options *processor.Options, file *processor.File) error {
+
+	if !IsFavicon(file) {
+		return nil
+	}
+
+	// Convert is optional for the plugins, but required to generate the favicon
+	if !processor.CheckTool(options, Convert).Found {
+		return fmt.Errorf(
+			"favicon generation requires missing tools: %s (%s)",
+			Convert.Name,
+			Convert.Install,
+		)
+	}
+
+	folder := system.Dir(file.Destination)
+
+	err := CreateFavicon(ctx, options, file.Path, filepath.Join(folder, Favicon), FaviconSizes)
+	if err != nil {
+		return err
+	}
+
+	for _, icon := range Icons {
+		err := CreateIcon(ctx, options, file.Path, filepath.Join(folder, icon.File), icon.Size)
+		if err != nil {
+			return err
+		}
+	}
+
+	return CreateManifest(options, file, filepath.Join(folder, Manifest))
+}
+
+// Transform processor
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
+
+	err := system.Copy(file.Path, file.Destination)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Plugin return the compactor plugin instance
+func Plugin() *processor.Plugin {
+	return &processor.Plugin{
+		Namespace:  "ico",
+		Extensions: []string{".ico"},
+		Init:       generic.Init,
+		Shutdown:   generic.Shutdown,
+		Resolve:    generic.Resolve,
+		Related:    generic.Related,
+		Transform:  Transform,
+		Optimize:   generic.Optimize,
+	}
+}
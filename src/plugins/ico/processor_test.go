@@ -0,0 +1,24 @@
+package ico
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mateussouzaweb/compactor/src/processor"
+)
+
+func TestGenerateMissingConvert(t *testing.T) {
+
+	options := &processor.Options{
+		Tools: processor.Tools{Path: map[string]string{"convert": "missing-convert-tool"}},
+	}
+
+	file := &processor.File{Path: "/src/favicon.svg", Name: "favicon", Extension: ".svg"}
+	err := Generate(context.Background(), options, file)
+
+	if err == nil || !strings.Contains(err.Error(), "requires missing tools: convert (apt install imagemagick)") {
+		t.Errorf("Generate() error = %v, expected missing convert tool", err)
+	}
+
+}
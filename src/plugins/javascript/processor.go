@@ -1,6 +1,7 @@
 package javascript
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -15,9 +16,8 @@ func Resolve(options *processor.Options, file *processor.File) (string, error) {
 	destination := options.ToDestination(file.Path)
 	destination = options.ToExtension(destination, ".js")
 
-	if options.Destination.Hashed {
-		hash := file.Checksum[len(file.Checksum)-1]
-		destination = options.ToHashed(destination, hash)
+	if options.ShouldHash(file.Path, true) {
+		destination = processor.HashDestination(options, file, destination)
 	}
 
 	return destination, nil
@@ -37,12 +37,12 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 		Dependency: true,
 		Source:     "",
 		Path:       system.File(fileMap),
-		File:       processor.GetFile(fileMap),
+		File:       options.Builder().GetFile(fileMap),
 	})
 
 	// Detect imports
 	regex := regexp.MustCompile(`import ?((.+) ?from ?)?("(.+)"|'(.+)');?`)
-	matches := regex.FindAllStringSubmatch(file.Content, -1)
+	matches := regex.FindAllStringSubmatch(file.Content(), -1)
 	extensions := []string{".js", ".mjs"}
 
 	for _, match := range matches {
@@ -50,13 +50,13 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 		path := strings.Trim(match[3], `'"`)
 		filePath := system.Resolve(path, extensions, system.Dir(file.Path))
 
-		if processor.GetFile(filePath).Path != "" {
+		if options.Builder().GetFile(filePath).Path != "" {
 			related = append(related, processor.Related{
 				Type:       "import",
 				Dependency: false,
 				Source:     source,
 				Path:       path,
-				File:       processor.GetFile(filePath),
+				File:       options.Builder().GetFile(filePath),
 			})
 		}
 	}
@@ -64,8 +64,15 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 	return related, nil
 }
 
+// Terser tool
+var Terser = processor.Tool{
+	Name:    "terser",
+	Version: []string{"--version"},
+	Install: "npm install -g terser",
+}
+
 // Transform processor
-func Transform(options *processor.Options, file *processor.File) error {
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	files := []string{file.Path}
 
@@ -94,7 +101,7 @@ func Transform(options *processor.Options, file *processor.File) error {
 		}, ","))
 	}
 
-	_, err := system.Exec("terser", args...)
+	_, err := processor.ExecTool(ctx, options, Terser, args...)
 	if err != nil {
 		return err
 	}
@@ -107,6 +114,7 @@ func Plugin() *processor.Plugin {
 	return &processor.Plugin{
 		Namespace:  "javascript",
 		Extensions: []string{".js", ".mjs"},
+		Tools:      []processor.Tool{Terser},
 		Init:       generic.Init,
 		Shutdown:   generic.Shutdown,
 		Resolve:    Resolve,
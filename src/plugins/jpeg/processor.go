@@ -1,6 +1,8 @@
 package jpeg
 
 import (
+	"context"
+
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
 	"github.com/mateussouzaweb/compactor/src/plugins/webp"
 	"github.com/mateussouzaweb/compactor/src/processor"
@@ -19,14 +21,21 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 		Dependency: true,
 		Source:     "",
 		Path:       system.File(filePath),
-		File:       processor.GetFile(filePath),
+		File:       options.Builder().GetFile(filePath),
 	})
 
 	return related, nil
 }
 
+// Jpegoptim tool
+var Jpegoptim = processor.Tool{
+	Name:    "jpegoptim",
+	Version: []string{"--version"},
+	Install: "apt install jpegoptim or npm install -g jpegoptim-bin",
+}
+
 // Transform processor
-func Transform(options *processor.Options, file *processor.File) error {
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	err := system.Copy(file.Path, file.Destination)
 	if err != nil {
@@ -37,11 +46,13 @@ func Transform(options *processor.Options, file *processor.File) error {
 }
 
 // Optimize processor
-func Optimize(options *processor.Options, file *processor.File) error {
+func Optimize(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	if options.ShouldCompress(file.Path) {
-		_, err := system.Exec(
-			"jpegoptim",
+		_, err := processor.ExecTool(
+			ctx,
+			options,
+			Jpegoptim,
 			"--quiet",
 			"--strip-all",
 			"--all-progressive",
@@ -55,7 +66,7 @@ func Optimize(options *processor.Options, file *processor.File) error {
 	}
 
 	if options.ShouldGenerateProgressive(file.Path) {
-		err := webp.CreateCopy(file.Path, file.Destination, 75)
+		err := webp.CreateCopy(ctx, options, file.Path, file.Destination, 75)
 		if err != nil {
 			return err
 		}
@@ -69,6 +80,7 @@ func Plugin() *processor.Plugin {
 	return &processor.Plugin{
 		Namespace:  "jpeg",
 		Extensions: []string{".jpeg", ".jpg"},
+		Tools:      []processor.Tool{Jpegoptim, webp.Cwebp},
 		Init:       generic.Init,
 		Shutdown:   generic.Shutdown,
 		Resolve:    generic.Resolve,
@@ -1,7 +1,10 @@
 package png
 
 import (
+	"context"
+
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
+	"github.com/mateussouzaweb/compactor/src/plugins/ico"
 	"github.com/mateussouzaweb/compactor/src/plugins/webp"
 	"github.com/mateussouzaweb/compactor/src/processor"
 	"github.com/mateussouzaweb/compactor/src/system"
@@ -19,14 +22,24 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 		Dependency: true,
 		Source:     "",
 		Path:       system.File(filePath),
-		File:       processor.GetFile(filePath),
+		File:       options.Builder().GetFile(filePath),
 	})
 
+	// Add possible favicon generated files
+	related = append(related, ico.FaviconRelated(options, file)...)
+
 	return related, nil
 }
 
+// Optipng tool
+var Optipng = processor.Tool{
+	Name:    "optipng",
+	Version: []string{"--version"},
+	Install: "apt install optipng or npm install -g optipng-bin",
+}
+
 // Transform processor
-func Transform(options *processor.Options, file *processor.File) error {
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	err := system.Copy(file.Path, file.Destination)
 	if err != nil {
@@ -37,11 +50,13 @@ func Transform(options *processor.Options, file *processor.File) error {
 }
 
 // Optimize processor
-func Optimize(options *processor.Options, file *processor.File) error {
+func Optimize(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	if options.ShouldCompress(file.Path) {
-		_, err := system.Exec(
-			"optipng",
+		_, err := processor.ExecTool(
+			ctx,
+			options,
+			Optipng,
 			"--quiet",
 			file.Destination,
 		)
@@ -52,12 +67,17 @@ func Optimize(options *processor.Options, file *processor.File) error {
 	}
 
 	if options.ShouldGenerateProgressive(file.Path) {
-		err := webp.CreateCopy(file.Path, file.Destination, 75)
+		err := webp.CreateCopy(ctx, options, file.Path, file.Destination, 75)
 		if err != nil {
 			return err
 		}
 	}
 
+	err := ico.Generate(ctx, options, file)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -66,6 +86,7 @@ func Plugin() *processor.Plugin {
 	return &processor.Plugin{
 		Namespace:  "png",
 		Extensions: []string{".png"},
+		Tools:      []processor.Tool{Optipng, webp.Cwebp, ico.Convert},
 		Init:       generic.Init,
 		Shutdown:   generic.Shutdown,
 		Resolve:    generic.Resolve,
@@ -1,24 +1,93 @@
 package sass
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
 	"github.com/mateussouzaweb/compactor/src/processor"
 	"github.com/mateussouzaweb/compactor/src/system"
 )
 
-var _service TranspilerService
+// Node tool
+var Node = processor.Tool{
+	Name:    "node",
+	Version: []string{"--version"},
+	Install: "apt install nodejs npm",
+}
+
+// Tools required by the transpiler service
+var Tools = []processor.Tool{
+	Node,
+	{
+		Name:    "sass-embedded",
+		Module:  true,
+		Install: "npm install -g sass-embedded",
+	},
+}
+
+// Processor struct
+// Holds the transpiler service shared by the sass and css plugins of the builder
+type Processor struct {
+	Service TranspilerService
+	plugins int
+}
+
+// Processors of each builder
+var processors = make(map[*processor.Builder]*Processor)
+var mutex sync.Mutex
 
 // Init processor
+// Service starts with the first initialized plugin of the builder
 func Init(options *processor.Options) error {
-	return _service.Init()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	builder := options.Builder()
+	if p, ok := processors[builder]; ok {
+		p.plugins++
+		return nil
+	}
+
+	p := &Processor{plugins: 1}
+	err := p.Service.Init(options)
+	if err != nil {
+		return err
+	}
+
+	processors[builder] = p
+
+	return nil
 }
 
 // Shutdown processor
+// Service stops with the last plugin of the builder
 func Shutdown(options *processor.Options) error {
-	return _service.Shutdown()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	builder := options.Builder()
+	p, ok := processors[builder]
+	if !ok {
+		return nil
+	}
+
+	p.plugins--
+	if p.plugins > 0 {
+		return nil
+	}
+
+	delete(processors, builder)
+
+	return p.Service.Shutdown()
 }
 
 // Resolve processor
@@ -27,9 +96,8 @@ func Resolve(options *processor.Options, file *processor.File) (string, error) {
 	destination := options.ToDestination(file.Path)
 	destination = options.ToExtension(destination, ".css")
 
-	if options.Destination.Hashed {
-		hash := file.Checksum[len(file.Checksum)-1]
-		destination = options.ToHashed(destination, hash)
+	if options.ShouldHash(file.Path, true) {
+		destination = processor.HashDestination(options, file, destination)
 	}
 
 	return destination, nil
@@ -49,26 +117,52 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 		Dependency: true,
 		Source:     "",
 		Path:       system.File(fileMap),
-		File:       processor.GetFile(fileMap),
+		File:       options.Builder().GetFile(fileMap),
 	})
 
 	// Detect imports
-	regex := regexp.MustCompile(`@import ?("(.+)"|'(.+)');?`)
-	matches := regex.FindAllStringSubmatch(file.Content, -1)
-	extensions := []string{".scss", ".sass", ".css"}
+	regex := regexp.MustCompile(`@(?:import|use|forward) ?("([^"]+)"|'([^']+)');?`)
+	matches := regex.FindAllStringSubmatch(file.Content(), -1)
 
 	for _, match := range matches {
 		source := match[0]
 		path := strings.Trim(match[1], `'"`)
-		filePath := system.Resolve(path, extensions, system.Dir(file.Path))
+		filePath := ResolveImport(path, system.Dir(file.Path))
 
-		if processor.GetFile(filePath).Path != "" {
+		if options.Builder().GetFile(filePath).Path != "" {
 			related = append(related, processor.Related{
 				Type:       "import",
 				Dependency: true,
 				Source:     source,
 				Path:       path,
-				File:       processor.GetFile(filePath),
+				File:       options.Builder().GetFile(filePath),
+			})
+		}
+	}
+
+	// Detect assets
+	regex = regexp.MustCompile(`url\(\s*("([^"]+)"|'([^']+)'|([^'")]+))\s*\)`)
+	matches = regex.FindAllStringSubmatch(file.Content(), -1)
+
+	for _, match := range matches {
+		source := match[0]
+		path := strings.TrimSpace(strings.Trim(match[1], `'"`))
+
+		// Ignore protocol and data assets, only handle relative and absolute paths
+		if strings.Contains(path, ":") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "#") {
+			continue
+		}
+
+		clean := strings.SplitN(strings.SplitN(path, "?", 2)[0], "#", 2)[0]
+		filePath := system.Resolve(clean, []string{}, system.Dir(file.Path))
+
+		if options.Builder().GetFile(filePath).Path != "" {
+			related = append(related, processor.Related{
+				Type:       "asset",
+				Dependency: false,
+				Source:     source,
+				Path:       path,
+				File:       options.Builder().GetFile(filePath),
 			})
 		}
 	}
@@ -76,8 +170,148 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 	return related, nil
 }
 
+// ResolveImport returns the path of the stylesheet loaded by the import, including partials and index files
+func ResolveImport(path string, folder string) string {
+
+	extensions := []string{".scss", ".sass", ".css"}
+	file := filepath.Join(folder, path)
+	candidates := []string{file, filepath.Join(system.Dir(file), "_"+system.File(file))}
+
+	if !slices.Contains(extensions, system.Extension(file)) {
+		candidates = []string{}
+		for _, extension := range extensions {
+			candidates = append(candidates, file+extension)
+			candidates = append(candidates, filepath.Join(system.Dir(file), "_"+system.File(file)+extension))
+		}
+		for _, extension := range extensions {
+			candidates = append(candidates, filepath.Join(file, "index"+extension))
+			candidates = append(candidates, filepath.Join(file, "_index"+extension))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if system.Exist(candidate) {
+			return candidate
+		}
+	}
+
+	return system.Resolve(path, extensions, folder)
+}
+
+// AssetReferences returns the assets referenced on the file and its imported stylesheets by the URL on the compiled output
+// Transpiler rebases relative references of imported stylesheets to the file folder, so each URL points to a single asset
+func AssetReferences(file *processor.File) map[string]processor.Related {
+
+	references := make(map[string]processor.Related)
+	visited := make(map[string]bool)
+
+	var walk func(item *processor.File)
+	walk = func(item *processor.File) {
+
+		if visited[item.Path] {
+			return
+		}
+
+		visited[item.Path] = true
+
+		for _, related := range item.Related {
+			switch related.Type {
+			case "asset":
+				url := related.Path
+				if item != file && !strings.HasPrefix(url, "/") {
+					url = system.Relative(system.Dir(file.Path), filepath.Join(system.Dir(item.Path), url))
+					url = filepath.ToSlash(url)
+				}
+				references[url] = related
+			case "import":
+				walk(related.File)
+			}
+		}
+
+	}
+
+	walk(file)
+
+	return references
+}
+
+// ReplaceURL replaces the path of every CSS url() reference that matches the path
+func ReplaceURL(content string, path string, replacement string) string {
+
+	regex := regexp.MustCompile(`url\(\s*["']?` + regexp.QuoteMeta(path) + `["']?\s*\)`)
+	content = regex.ReplaceAllLiteralString(content, `url("`+replacement+`")`)
+
+	return content
+}
+
+// AssetURL returns the final URL of the asset referenced on the CSS file
+// Query and hash from the original path are preserved
+func AssetURL(options *processor.Options, file *processor.File, related processor.Related) string {
+
+	path := related.Path
+	suffix := ""
+
+	if index := strings.IndexAny(path, "?#"); index != -1 {
+		suffix = path[index:]
+	}
+
+	url := system.Relative(system.Dir(file.Destination), related.File.Destination)
+	if strings.HasPrefix(path, "/") {
+		url = "/" + options.CleanPath(related.File.Destination)
+	}
+
+	url = options.ToVersioned(url, related.File.Hash)
+	if strings.Contains(url, "?") && strings.HasPrefix(suffix, "?") {
+		suffix = "&" + strings.TrimPrefix(suffix, "?")
+	}
+
+	return url + suffix
+}
+
+// UpdateAssets embeds the small assets referenced on CSS as data URIs
+// Other assets have their references updated to the final destination path
+func UpdateAssets(options *processor.Options, file *processor.File) error {
+
+	content, err := system.Read(file.Destination)
+	if err != nil {
+		return err
+	}
+
+	updated := content
+	references := AssetReferences(file)
+
+	for _, url := range slices.Sorted(maps.Keys(references)) {
+
+		related := references[url]
+		force := processor.ShouldForceInline(related.Path)
+		inline, ok := processor.InlineContent(options, related.File, force)
+
+		if ok {
+			dataURI := system.DataURI(inline, related.File.Destination)
+			updated = ReplaceURL(updated, url, dataURI)
+		} else if related.File.Destination != "" {
+			updated = ReplaceURL(updated, url, AssetURL(options, file, related))
+		}
+
+	}
+
+	if updated == content {
+		return nil
+	}
+
+	return system.Write(file.Destination, updated, file.Permission)
+}
+
 // Transform processor
-func Transform(options *processor.Options, file *processor.File) error {
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
+
+	mutex.Lock()
+	p, ok := processors[options.Builder()]
+	mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("transpiler service is not initialized")
+	}
 
 	// Create config
 	config := &SassConfig{
@@ -93,7 +327,13 @@ func Transform(options *processor.Options, file *processor.File) error {
 	}
 
 	// Run transpilation
-	err := _service.Execute(config, file)
+	err := p.Service.Execute(ctx, config, file)
+	if err != nil {
+		return err
+	}
+
+	// Update assets references on the final output
+	err = UpdateAssets(options, file)
 	if err != nil {
 		return err
 	}
@@ -106,6 +346,7 @@ func Plugin() *processor.Plugin {
 	return &processor.Plugin{
 		Namespace:  "sass",
 		Extensions: []string{".sass", ".scss", ".css"},
+		Tools:      Tools,
 		Init:       Init,
 		Shutdown:   Shutdown,
 		Resolve:    Resolve,
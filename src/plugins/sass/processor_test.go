@@ -0,0 +1,95 @@
+package sass
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+func TestResolveImport(t *testing.T) {
+
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "a"), 0755)
+	os.MkdirAll(filepath.Join(root, "b"), 0755)
+	system.Write(filepath.Join(root, "a", "_x.scss"), "", 0644)
+	system.Write(filepath.Join(root, "b", "_index.scss"), "", 0644)
+	system.Write(filepath.Join(root, "c.css"), "", 0644)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"a/x", filepath.Join(root, "a", "_x.scss")},
+		{"a/_x.scss", filepath.Join(root, "a", "_x.scss")},
+		{"b", filepath.Join(root, "b", "_index.scss")},
+		{"c", filepath.Join(root, "c.css")},
+	}
+
+	for _, test := range tests {
+		result := ResolveImport(test.path, root)
+		if result != test.expected {
+			t.Errorf("ResolveImport(%q) = %q, expected %q", test.path, result, test.expected)
+		}
+	}
+
+}
+
+func TestUpdateAssets(t *testing.T) {
+
+	root := t.TempDir()
+	options := &processor.Options{
+		Source:      processor.Source{Path: filepath.Join(root, "src")},
+		Destination: processor.Destination{Path: filepath.Join(root, "dist")},
+	}
+
+	asset := func(path string, destination string) processor.Related {
+		return processor.Related{
+			Type: "asset",
+			Path: "img/icon.png",
+			File: &processor.File{
+				Path:        filepath.Join(options.Source.Path, path),
+				Destination: filepath.Join(options.Destination.Path, destination),
+			},
+		}
+	}
+
+	// Partials of different folders reference the same URL of different assets
+	partialA := &processor.File{
+		Path:    filepath.Join(options.Source.Path, "a", "_x.scss"),
+		Related: []processor.Related{asset("a/img/icon.png", "a/img/icon.1.png")},
+	}
+	partialB := &processor.File{
+		Path:    filepath.Join(options.Source.Path, "b", "_index.scss"),
+		Related: []processor.Related{asset("b/img/icon.png", "b/img/icon.2.png")},
+	}
+
+	file := &processor.File{
+		Path:        filepath.Join(options.Source.Path, "main.scss"),
+		Destination: filepath.Join(options.Destination.Path, "main.css"),
+		Permission:  0644,
+		Related: []processor.Related{
+			{Type: "import", Dependency: true, Path: "a/x", File: partialA},
+			{Type: "import", Dependency: true, Path: "b", File: partialB},
+			asset("img/icon.png", "img/icon.3.png"),
+		},
+	}
+
+	// Compiled output with references of partials rebased by the transpiler
+	os.MkdirAll(options.Destination.Path, 0755)
+	system.Write(file.Destination, `.a{background:url("a/img/icon.png")}.b{background:url("b/img/icon.png")}.c{background:url(img/icon.png)}`, 0644)
+
+	err := UpdateAssets(options, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := system.Read(file.Destination)
+	expected := `.a{background:url("a/img/icon.1.png")}.b{background:url("b/img/icon.2.png")}.c{background:url("img/icon.3.png")}`
+	if content != expected {
+		t.Errorf("UpdateAssets() = %s, expected %s", content, expected)
+	}
+
+}
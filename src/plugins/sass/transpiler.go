@@ -2,13 +2,14 @@ package sass
 
 import (
 	"bytes"
+	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"os/exec"
+	"sync/atomic"
 	"time"
 
 	"github.com/mateussouzaweb/compactor/src/errors"
@@ -25,12 +26,17 @@ type TranspilerService struct {
 	Port    string
 	Address string
 	Cmd     *exec.Cmd
+	Closed  atomic.Bool
+	cancel  context.CancelFunc
+	exited  chan struct{}
+	err     error
 }
 
 // Init service to handle transpilation requests
-func (service *TranspilerService) Init() error {
+func (service *TranspilerService) Init(options *processor.Options) error {
 
 	var err error
+	service.Closed.Store(false)
 
 	// Write server script to temporary file
 	file := system.TemporaryFile("sass-transpiler.js")
@@ -54,31 +60,43 @@ func (service *TranspilerService) Init() error {
 		return err
 	}
 
-	// Run server in background
-	cmd := exec.Command("node", file)
-	cmd.Env = append(os.Environ(), "PORT="+port)
+	// Run server in background on its own process group
+	// Modules are loaded from the detected node modules folder
+	env := []string{"PORT=" + port}
+	env = append(env, "NODE_MODULES="+processor.FindNodeModules(options, "sass-embedded"))
+	env = append(env, options.Tools.Env[Node.Name]...)
 
-	err = cmd.Start()
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, err := system.StartContext(ctx, processor.ResolveTool(options, Node), env, file)
 	if err != nil {
+		cancel()
 		return err
 	}
 
-	go func() error {
+	exited := make(chan struct{})
+	go func() {
 		err := cmd.Wait()
-		if err != nil {
-			panic(err)
+		if !service.Closed.Load() {
+			service.err = fmt.Errorf("transpiler service exited: %v", err)
 		}
-		return nil
+		close(exited)
 	}()
 
 	// Wait service become online
 	address := "http://localhost:" + port
 	for {
-		_, err := http.Get(address)
+		response, err := http.Get(address)
 		if err == nil {
+			response.Body.Close()
 			break
 		}
-		time.Sleep(10 * time.Millisecond)
+
+		select {
+		case <-exited:
+			cancel()
+			return service.err
+		case <-time.After(10 * time.Millisecond):
+		}
 	}
 
 	// Set service data
@@ -86,6 +104,8 @@ func (service *TranspilerService) Init() error {
 	service.Port = port
 	service.Address = address
 	service.Cmd = cmd
+	service.cancel = cancel
+	service.exited = exited
 
 	return err
 }
@@ -93,11 +113,12 @@ func (service *TranspilerService) Init() error {
 // Shutdown transpilation service
 func (service *TranspilerService) Shutdown() error {
 
-	if service.Cmd.Process.Pid != 0 {
-		err := service.Cmd.Process.Kill()
-		if err != nil {
-			return err
-		}
+	service.Closed.Store(true)
+
+	// Cancellation kills the whole process group
+	if service.cancel != nil {
+		service.cancel()
+		<-service.exited
 	}
 
 	if service.File != "" {
@@ -111,7 +132,16 @@ func (service *TranspilerService) Shutdown() error {
 }
 
 // Execute transpilation process
-func (service *TranspilerService) Execute(config *SassConfig, file *processor.File) error {
+func (service *TranspilerService) Execute(ctx context.Context, config *SassConfig, file *processor.File) error {
+
+	select {
+	case <-service.exited:
+		if service.err != nil {
+			return service.err
+		}
+		return fmt.Errorf("transpiler service is closed")
+	default:
+	}
 
 	relative := system.Relative(system.Dir(file.Destination), file.Path)
 	data := struct {
@@ -129,15 +159,22 @@ func (service *TranspilerService) Execute(config *SassConfig, file *processor.Fi
 		return err
 	}
 
-	response, err := http.Post(
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
 		service.Address,
-		"application/json",
 		bytes.NewBuffer(body),
 	)
 	if err != nil {
 		return err
 	}
 
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+
 	defer errors.Join(&err, func() error {
 		return response.Body.Close()
 	})
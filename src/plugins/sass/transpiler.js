@@ -1,10 +1,79 @@
 const { execSync } = require("child_process")
-const root = execSync("npm root -g").toString().trim()
+const root = process.env.NODE_MODULES || execSync("npm root -g").toString().trim()
 const sass = require(root + "/sass-embedded")
+const fs = require("fs")
 const http = require("http")
 const path = require("path")
+const { fileURLToPath, pathToFileURL } = require("url")
 const port = process.env.PORT || 3000
 
+// Resolve the stylesheet file of the import, including partials and index files
+const resolve = (file) => {
+
+    const folder = path.dirname(file)
+    const name = path.basename(file)
+    const extensions = [".scss", ".sass", ".css"]
+    const candidates = []
+
+    if (extensions.includes(path.extname(name))) {
+        candidates.push(file, path.join(folder, "_" + name))
+    } else {
+        for (const extension of extensions) {
+            candidates.push(file + extension, path.join(folder, "_" + name + extension))
+        }
+        for (const extension of extensions) {
+            candidates.push(path.join(file, "index" + extension), path.join(file, "_index" + extension))
+        }
+    }
+
+    return candidates.find((candidate) => {
+        return fs.existsSync(candidate) && fs.statSync(candidate).isFile()
+    })
+}
+
+// Rebase relative url() references of the imported stylesheet to the main file folder
+// Sass keeps references as written, so each one must point to the same file from the compiled output
+const rebase = (content, folder, base) => {
+
+    const regex = /url\(\s*("([^"]+)"|'([^']+)'|([^'")]+))\s*\)/g
+
+    return content.replace(regex, (source, value) => {
+
+        const url = value.replace(/^["']|["']$/g, "").trim()
+        if (/[:$]|#\{/.test(url) || url.startsWith("/") || url.startsWith("#")) {
+            return source
+        }
+
+        const rebased = path.relative(base, path.resolve(folder, url))
+        return `url("${rebased.split(path.sep).join("/")}")`
+    })
+}
+
+// Importer that loads stylesheets from disk with url() references rebased to the main file
+const importer = (base) => ({
+    canonicalize(url) {
+        if (url.startsWith("file:")) {
+            const file = resolve(fileURLToPath(url))
+            return file ? pathToFileURL(file) : null
+        }
+        if (/^[a-z][a-z0-9+.-]*:/i.test(url)) {
+            return null
+        }
+        const file = resolve(path.resolve(base, url))
+        return file ? pathToFileURL(file) : null
+    },
+    load(canonicalUrl) {
+        const file = fileURLToPath(canonicalUrl)
+        const extension = path.extname(file)
+        const content = fs.readFileSync(file, "utf8")
+        return {
+            contents: rebase(content, path.dirname(file), base),
+            syntax: extension === ".sass" ? "indented" : extension === ".css" ? "css" : "scss",
+            sourceMapUrl: canonicalUrl
+        }
+    }
+})
+
 const httpServer = http.createServer(async (request, response) => {
     try {
 
@@ -23,6 +92,7 @@ const httpServer = http.createServer(async (request, response) => {
         const content = file.content || ""
         const config = body.config || {}
         config.loadPaths = [path.dirname(file.path)]
+        config.importers = [importer(path.dirname(file.path))]
 
         const result = await sass.compileStringAsync(content, config);
         const output = result.css ? result.css.toString() : ""
@@ -1,56 +1,55 @@
 package svg
 
 import (
+	"context"
+
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
+	"github.com/mateussouzaweb/compactor/src/plugins/ico"
 	"github.com/mateussouzaweb/compactor/src/processor"
 	"github.com/mateussouzaweb/compactor/src/system"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/svg"
 )
 
 // Minify SVG content
+// View box attribute is always preserved, so images keep scaling properly
 func Minify(content string) (string, error) {
 
-	config := system.TemporaryFile("svgo.config.js")
-	file := system.TemporaryFile("svgo.svg")
-	defer system.Delete(config)
-	defer system.Delete(file)
+	m := minify.New()
+	m.AddFunc("text/css", css.Minify)
+	m.Add("generic", &svg.Minifier{
+		KeepComments: false,
+		Precision:    0,
+	})
 
-	settings := "module.exports = {plugins: [{ name: 'removeViewBox', active: false }]}"
-	err := system.Write(config, settings, 0775)
-	if err != nil {
-		return content, err
-	}
+	content, err := m.String("generic", content)
 
-	err = system.Write(file, content, 0775)
-	if err != nil {
-		return content, err
-	}
+	return content, err
+}
 
-	_, err = system.Exec(
-		"svgo",
-		"--quiet",
-		"--config", config,
-		"--input", file,
-		"--output", file,
-	)
+// Related processor
+func Related(options *processor.Options, file *processor.File) ([]processor.Related, error) {
 
-	if err != nil {
-		return content, err
-	}
+	var related []processor.Related
 
-	content, err = system.Read(file)
+	// Add possible favicon generated files
+	related = append(related, ico.FaviconRelated(options, file)...)
 
-	return content, err
+	// Add possible sprite icons
+	related = append(related, SpriteRelated(options, file)...)
+
+	return related, nil
 }
 
-// Optimize processor
-func Optimize(options *processor.Options, file *processor.File) error {
+// Transform processor
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
 
-	if !options.ShouldCompress(file.Path) {
-		return nil
+	if !IsSprite(file) {
+		return generic.Transform(ctx, options, file)
 	}
 
-	content := file.Content
-	content, err := Minify(content)
+	content, err := CreateSprite(options, file)
 	if err != nil {
 		return err
 	}
@@ -65,16 +64,46 @@ func Optimize(options *processor.Options, file *processor.File) error {
 	return nil
 }
 
+// Optimize processor
+func Optimize(ctx context.Context, options *processor.Options, file *processor.File) error {
+
+	// Sprites are already created with minified icons
+	if options.ShouldCompress(file.Path) && !IsSprite(file) {
+
+		content := file.Content()
+		content, err := Minify(content)
+		if err != nil {
+			return err
+		}
+
+		destination := file.Destination
+		perm := file.Permission
+		err = system.Write(destination, content, perm)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	err := ico.Generate(ctx, options, file)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Plugin return the compactor plugin instance
 func Plugin() *processor.Plugin {
 	return &processor.Plugin{
 		Namespace:  "svg",
 		Extensions: []string{".svg"},
+		Tools:      []processor.Tool{ico.Convert},
 		Init:       generic.Init,
 		Shutdown:   generic.Shutdown,
 		Resolve:    generic.Resolve,
-		Related:    generic.Related,
-		Transform:  generic.Transform,
+		Related:    Related,
+		Transform:  Transform,
 		Optimize:   Optimize,
 	}
 }
@@ -0,0 +1,140 @@
+package svg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// IsSprite return if the given file is a sprite manifest
+// Every SVG file on the same folder of the manifest is merged into the sprite
+func IsSprite(file *processor.File) bool {
+	return strings.HasSuffix(file.File, ".sprite.svg")
+}
+
+// SymbolID returns the clean symbol ID for the given file path
+func SymbolID(path string) string {
+
+	regex := regexp.MustCompile(`[^a-z0-9_-]+`)
+	id := strings.ToLower(system.Name(path))
+	id = regex.ReplaceAllString(id, "-")
+	id = strings.Trim(id, "-")
+
+	return id
+}
+
+// SpriteRelated returns the list of icons that are merged on the sprite
+func SpriteRelated(options *processor.Options, file *processor.File) []processor.Related {
+
+	var related []processor.Related
+
+	if !IsSprite(file) {
+		return related
+	}
+
+	folder := system.Dir(file.Path)
+
+	for _, item := range options.Builder().GetFiles() {
+
+		if item.Extension != ".svg" || IsSprite(item) {
+			continue
+		}
+		if system.Dir(item.Path) != folder {
+			continue
+		}
+
+		related = append(related, processor.Related{
+			Type:       "symbol",
+			Dependency: true,
+			Source:     SymbolID(item.Path),
+			Path:       item.File,
+			File:       item,
+		})
+
+	}
+
+	return related
+}
+
+// ToSymbol converts the SVG content into a symbol with the given ID
+func ToSymbol(content string, id string) (string, error) {
+
+	regex := regexp.MustCompile(`(?s)<svg([^>]*)>(.*)</svg>`)
+	match := regex.FindStringSubmatch(content)
+
+	if match == nil {
+		return "", fmt.Errorf("invalid SVG content for symbol: %s", id)
+	}
+
+	attributes := match[1]
+	inner := strings.TrimSpace(match[2])
+
+	// Symbols require the view box to scale properly
+	viewBox := ""
+	regex = regexp.MustCompile(`viewBox=("([^"]*)"|'([^']*)')`)
+	found := regex.FindStringSubmatch(attributes)
+
+	if found != nil {
+		viewBox = strings.Trim(found[1], `'"`)
+	} else {
+		width := regexp.MustCompile(`width=["']([0-9.]+)`).FindStringSubmatch(attributes)
+		height := regexp.MustCompile(`height=["']([0-9.]+)`).FindStringSubmatch(attributes)
+		if width != nil && height != nil {
+			viewBox = "0 0 " + width[1] + " " + height[1]
+		}
+	}
+
+	symbol := `<symbol id="` + id + `"`
+	if viewBox != "" {
+		symbol += ` viewBox="` + viewBox + `"`
+	}
+	symbol += ">" + inner + "</symbol>"
+
+	return symbol, nil
+}
+
+// CreateSprite merges the related icons into a single symbol based SVG
+func CreateSprite(options *processor.Options, file *processor.File) (string, error) {
+
+	var symbols []string
+
+	for _, related := range file.Related {
+
+		if related.Type != "symbol" || !related.File.Exists {
+			continue
+		}
+
+		// Icons are minified individually
+		// Minify the whole sprite could remove the unreferenced symbols IDs
+		content := related.File.Content()
+		if options.ShouldCompress(related.File.Path) {
+			minified, err := Minify(content)
+			if err != nil {
+				return "", err
+			}
+			content = minified
+		}
+
+		symbol, err := ToSymbol(content, related.Source)
+		if err != nil {
+			return "", err
+		}
+
+		symbols = append(symbols, symbol)
+
+	}
+
+	separator := "\n"
+	if options.ShouldCompress(file.Path) {
+		separator = ""
+	}
+
+	sprite := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">`
+	sprite += separator + strings.Join(symbols, separator) + separator
+	sprite += `</svg>`
+
+	return sprite, nil
+}
@@ -1,30 +1,91 @@
 package typescript
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 
+	"github.com/mateussouzaweb/compactor/src/plugins/javascript"
 	"github.com/mateussouzaweb/compactor/src/processor"
 	"github.com/mateussouzaweb/compactor/src/system"
 )
 
-var _service TranspilerService
+// Node tool
+var Node = processor.Tool{
+	Name:    "node",
+	Version: []string{"--version"},
+	Install: "apt install nodejs npm",
+}
+
+// Tools required by the transpiler service and optimization
+var Tools = []processor.Tool{
+	Node,
+	{
+		Name:    "typescript",
+		Module:  true,
+		Install: "npm install -g typescript",
+	},
+	javascript.Terser,
+}
+
+// Processor struct
+// Holds the transpiler service and TypeScript config of the builder
+type Processor struct {
+	Service    TranspilerService
+	Config     *TSConfig
+	ConfigFile string
+}
+
+// Processors of each builder
+var processors = make(map[*processor.Builder]*Processor)
+var mutex sync.Mutex
+
+// GetProcessor retrieves the processor of the builder bound to the options
+// Processor is created on first use, because related files are detected before init
+func GetProcessor(options *processor.Options) *Processor {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	builder := options.Builder()
+	if p, ok := processors[builder]; ok {
+		return p
+	}
+
+	p := &Processor{}
+	processors[builder] = p
+
+	return p
+}
 
 // Init processor
 func Init(options *processor.Options) error {
 
-	err := _service.Init()
+	p := GetProcessor(options)
+	err := p.Service.Init(options)
 	if err != nil {
 		return err
 	}
 
-	return InitConfig(options.Source.Path)
+	return p.InitConfig(options.Source.Path)
 }
 
 // Shutdown processor
 func Shutdown(options *processor.Options) error {
-	return _service.Shutdown()
+
+	mutex.Lock()
+	builder := options.Builder()
+	p, ok := processors[builder]
+	delete(processors, builder)
+	mutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return p.Service.Shutdown()
 }
 
 // Resolve processor
@@ -33,9 +94,8 @@ func Resolve(options *processor.Options, file *processor.File) (string, error) {
 	destination := options.ToDestination(file.Path)
 	destination = options.ToExtension(destination, ".js")
 
-	if options.Destination.Hashed {
-		hash := file.Checksum[len(file.Checksum)-1]
-		destination = options.ToHashed(destination, hash)
+	if options.ShouldHash(file.Path, true) {
+		destination = processor.HashDestination(options, file, destination)
 	}
 
 	return destination, nil
@@ -45,10 +105,11 @@ func Resolve(options *processor.Options, file *processor.File) (string, error) {
 func Related(options *processor.Options, file *processor.File) ([]processor.Related, error) {
 
 	var related []processor.Related
+	p := GetProcessor(options)
 
 	// Read config if not loaded yet
-	if _tsConfigFile == "" {
-		err := InitConfig(file.Root)
+	if p.ConfigFile == "" {
+		err := p.InitConfig(file.Root)
 		if err != nil {
 			return related, err
 		}
@@ -63,7 +124,7 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 		Dependency: true,
 		Source:     "",
 		Path:       system.File(fileMap),
-		File:       processor.GetFile(fileMap),
+		File:       options.Builder().GetFile(fileMap),
 	})
 
 	// Add possible type declaration
@@ -73,27 +134,27 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 		Dependency: true,
 		Source:     "",
 		Path:       system.File(declaration),
-		File:       processor.GetFile(declaration),
+		File:       options.Builder().GetFile(declaration),
 	})
 
 	// Detect imports
 	regex := regexp.MustCompile(`import ?((.+) ?from ?)?("(.+)"|'(.+)');?`)
-	matches := regex.FindAllStringSubmatch(file.Content, -1)
+	matches := regex.FindAllStringSubmatch(file.Content(), -1)
 	extensions := []string{".js", ".mjs", ".jsx", ".ts", ".mts", ".tsx"}
 
 	for _, match := range matches {
 		source := match[0]
 		path := strings.Trim(match[3], `'"`)
-		thePath := FindRealPath(path)
+		thePath := p.FindRealPath(path)
 		filePath := system.Resolve(thePath, extensions, system.Dir(file.Path))
 
-		if processor.GetFile(filePath).Path != "" {
+		if options.Builder().GetFile(filePath).Path != "" {
 			related = append(related, processor.Related{
 				Type:       "import",
 				Dependency: false,
 				Source:     source,
 				Path:       path,
-				File:       processor.GetFile(filePath),
+				File:       options.Builder().GetFile(filePath),
 			})
 		}
 	}
@@ -102,9 +163,9 @@ func Related(options *processor.Options, file *processor.File) ([]processor.Rela
 }
 
 // FindRealPath transform TSConfig paths into real path values
-func FindRealPath(path string) string {
+func (p *Processor) FindRealPath(path string) string {
 
-	paths, ok := _tsConfig.CompilerOptions["paths"].(map[string]any)
+	paths, ok := p.Config.CompilerOptions["paths"].(map[string]any)
 
 	if ok {
 		for key, values := range paths {
@@ -119,10 +180,12 @@ func FindRealPath(path string) string {
 }
 
 // Transform processor
-func Transform(options *processor.Options, file *processor.File) error {
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
+
+	p := GetProcessor(options)
 
 	// Copy from user config file
-	config := *_tsConfig
+	config := *p.Config
 
 	if config.CompilerOptions == nil {
 		config.CompilerOptions = make(map[string]any)
@@ -146,7 +209,7 @@ func Transform(options *processor.Options, file *processor.File) error {
 	}
 
 	// Run transpilation
-	err := _service.Execute(&config, file)
+	err := p.Service.Execute(ctx, &config, file)
 	if err != nil {
 		return err
 	}
@@ -160,6 +223,8 @@ func Transform(options *processor.Options, file *processor.File) error {
 				relativePath = "./" + relativePath
 			}
 
+			relativePath = options.ToVersioned(relativePath, related.File.Hash)
+
 			oldSource := related.Source
 			firstIndex := strings.LastIndex(oldSource, related.Path)
 			lastIndex := firstIndex + len(related.Path)
@@ -182,7 +247,7 @@ func Transform(options *processor.Options, file *processor.File) error {
 }
 
 // Optimize processor
-func Optimize(options *processor.Options, file *processor.File) error {
+func Optimize(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	if !options.ShouldCompress(file.Path) {
 		return nil
@@ -208,7 +273,7 @@ func Optimize(options *processor.Options, file *processor.File) error {
 
 	}
 
-	_, err := system.Exec("terser", args...)
+	_, err := processor.ExecTool(ctx, options, javascript.Terser, args...)
 	if err != nil {
 		return err
 	}
@@ -221,6 +286,7 @@ func Plugin() *processor.Plugin {
 	return &processor.Plugin{
 		Namespace:  "typescript",
 		Extensions: []string{".js", ".mjs", ".jsx", ".ts", ".mts", ".tsx"},
+		Tools:      Tools,
 		Init:       Init,
 		Shutdown:   Shutdown,
 		Resolve:    Resolve,
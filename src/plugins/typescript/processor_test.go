@@ -0,0 +1,112 @@
+package typescript
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mateussouzaweb/compactor/src/processor"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// write creates the file with its folder
+func write(t *testing.T, file string, content string) {
+
+	err := system.EnsureDirectory(file)
+	if err == nil {
+		err = system.Write(file, content, 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func TestConcurrentBuilders(t *testing.T) {
+
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not available")
+	}
+
+	// Stub module outputs the config target before the content
+	root := t.TempDir()
+	modules := filepath.Join(root, "node_modules")
+	write(t, filepath.Join(modules, "typescript", "package.json"), `{"name":"typescript","version":"0.0.0"}`)
+	write(t, filepath.Join(modules, "typescript", "index.js"), `module.exports = {
+		transpileModule: (content, config) => ({ outputText: "// " + config.compilerOptions.target + "\n" + content })
+	}`)
+
+	// Plugin is registered once and shared by the builders of the options
+	plugin := Plugin()
+	processor.AddPlugin(plugin)
+	defer processor.RemovePlugin(plugin.Namespace)
+
+	var builders []*processor.Builder
+	for _, name := range []string{"first", "second"} {
+
+		project := filepath.Join(root, name)
+		write(t, filepath.Join(project, "tsconfig.json"), `{"compilerOptions":{"target":"`+name+`"}}`)
+		write(t, filepath.Join(project, "src", "app.ts"), "const app = 1")
+
+		options := &processor.Options{
+			Source:      processor.Source{Path: filepath.Join(project, "src")},
+			Destination: processor.Destination{Path: filepath.Join(project, "dist")},
+			Tools: processor.Tools{
+				NodeModules: modules,
+				Path:        map[string]string{"terser": "true"},
+			},
+		}
+
+		builders = append(builders, options.Builder())
+
+	}
+
+	// Build both projects at the same time
+	build := func(builders ...*processor.Builder) {
+
+		var group sync.WaitGroup
+		for _, builder := range builders {
+			group.Go(func() {
+				err := builder.Build(context.Background())
+				if err != nil {
+					t.Error(err)
+				}
+			})
+		}
+
+		group.Wait()
+
+		for _, builder := range builders {
+			name := filepath.Base(filepath.Dir(builder.Options.Source.Path))
+			content, _ := system.Read(filepath.Join(builder.Options.Destination.Path, "app.js"))
+			if !strings.HasPrefix(content, "// "+name+"\n") {
+				t.Errorf("%s: expected output with its own config, got %q", name, content)
+			}
+		}
+
+	}
+
+	build(builders...)
+
+	// Shutdown of one builder keeps the service of the other
+	err := builders[0].Shutdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	system.Write(filepath.Join(builders[1].Options.Source.Path, "app.ts"), "const app = 2", 0644)
+	build(builders[1])
+
+	err = builders[1].Shutdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(processors) != 0 {
+		t.Errorf("expected every processor removed on shutdown, found %d", len(processors))
+	}
+
+}
@@ -1,5 +1,5 @@
 const { execSync } = require("child_process")
-const root = execSync("npm root -g").toString().trim()
+const root = process.env.NODE_MODULES || execSync("npm root -g").toString().trim()
 const ts = require(root + "/typescript")
 const http = require("http")
 const url = require("url")
@@ -19,9 +19,6 @@ type TSConfig struct {
 	References      []string       `json:"references,omitempty"`
 }
 
-var _tsConfig *TSConfig
-var _tsConfigFile string
-
 // FindConfig locate the user defined TypeScript config file
 func FindConfig(path string) string {
 
@@ -61,11 +58,11 @@ func ReadConfig(path string) (*TSConfig, error) {
 }
 
 // InitConfig find and read tsconfig file from given path
-func InitConfig(path string) error {
+func (p *Processor) InitConfig(path string) error {
 
 	var err error
-	_tsConfigFile = FindConfig(path)
-	_tsConfig, err = ReadConfig(_tsConfigFile)
+	p.ConfigFile = FindConfig(path)
+	p.Config, err = ReadConfig(p.ConfigFile)
 
 	return err
 }
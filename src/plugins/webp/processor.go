@@ -1,6 +1,7 @@
 package webp
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
@@ -8,11 +9,20 @@ import (
 	"github.com/mateussouzaweb/compactor/src/system"
 )
 
+// Cwebp tool
+var Cwebp = processor.Tool{
+	Name:    "cwebp",
+	Version: []string{"-version"},
+	Install: "apt install webp or npm install -g cwebp-bin",
+}
+
 // CreateCopy make a WEBP copy of a image file from almost any format
-func CreateCopy(source string, destination string, quality int) error {
+func CreateCopy(ctx context.Context, options *processor.Options, source string, destination string, quality int) error {
 
-	_, err := system.Exec(
-		"cwebp",
+	_, err := processor.ExecTool(
+		ctx,
+		options,
+		Cwebp,
 		"-q", fmt.Sprintf("%d", quality),
 		destination,
 		"-o", destination+".webp",
@@ -22,7 +32,7 @@ func CreateCopy(source string, destination string, quality int) error {
 }
 
 // Transform processor
-func Transform(options *processor.Options, file *processor.File) error {
+func Transform(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	err := system.Copy(file.Path, file.Destination)
 	if err != nil {
@@ -1,6 +1,8 @@
 package xml
 
 import (
+	"context"
+
 	"github.com/mateussouzaweb/compactor/src/plugins/generic"
 	"github.com/mateussouzaweb/compactor/src/processor"
 	"github.com/mateussouzaweb/compactor/src/system"
@@ -20,13 +22,13 @@ func Minify(content string) (string, error) {
 }
 
 // Optimize processor
-func Optimize(options *processor.Options, file *processor.File) error {
+func Optimize(ctx context.Context, options *processor.Options, file *processor.File) error {
 
 	if !options.ShouldCompress(file.Path) {
 		return nil
 	}
 
-	content := file.Content
+	content := file.Content()
 	content, err := Minify(content)
 	if err != nil {
 		return err
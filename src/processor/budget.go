@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Offender struct
+type Offender struct {
+	Budget Budget
+	Size   int64
+	Files  []string
+}
+
+// MeasureSize retrieves the size of the output file, gzipped when requested
+func MeasureSize(path string, gzipped bool) int64 {
+
+	if !gzipped {
+		return system.Size(path)
+	}
+
+	size, err := system.GzipSize(path)
+	if err != nil {
+		return system.Size(path)
+	}
+
+	return size
+}
+
+// CheckBudgets compares the final outputs of the packages against the size budgets
+// Pre-compressed copies are ignored, use gzip budgets to measure compressed sizes
+func CheckBudgets(options *Options, packages []*File) []Offender {
+
+	var offenders []Offender
+
+	for _, budget := range options.Budgets {
+
+		var total int64
+		var files []string
+
+		for _, file := range packages {
+			for _, output := range Outputs(file) {
+
+				precompressed := false
+				for _, extension := range PrecompressFormats {
+					precompressed = precompressed || strings.HasSuffix(output, extension)
+				}
+				if precompressed {
+					continue
+				}
+
+				// Patterns matches the file name without hash in any folder
+				name := options.ToNonHashed(output, file.Hash)
+				if !options.MatchNames(name, budget.Patterns) {
+					continue
+				}
+
+				path := options.CleanPath(output)
+				size := MeasureSize(output, budget.Gzip)
+
+				if budget.Total {
+					if !slices.Contains(files, path) {
+						total += size
+						files = append(files, path)
+					}
+					continue
+				}
+
+				if size > budget.Limit {
+					offenders = append(offenders, Offender{
+						Budget: budget,
+						Size:   size,
+						Files:  []string{path},
+					})
+				}
+
+			}
+		}
+
+		if budget.Total && total > budget.Limit {
+			offenders = append(offenders, Offender{
+				Budget: budget,
+				Size:   total,
+				Files:  files,
+			})
+		}
+
+	}
+
+	return offenders
+}
@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+func TestCheckBudgets(t *testing.T) {
+
+	root := t.TempDir()
+	options := &Options{
+		Source:      Source{Path: filepath.Join(root, "src")},
+		Destination: Destination{Path: filepath.Join(root, "dist"), Hashed: true},
+	}
+
+	// Creates the file and its output with the given size
+	create := func(name string, hash string, size int) *File {
+
+		file := &File{
+			Path:        options.ToSource(name),
+			Destination: options.ToHashed(options.ToDestination(name), hash),
+			Hash:        hash,
+		}
+
+		err := system.EnsureDirectory(file.Destination)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = system.Write(file.Destination, strings.Repeat("a", size), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return file
+	}
+
+	app := create("js/app.js", "a1b2", 2048)
+	vendor := create("js/vendor.js", "c3d4", 512)
+	page := create("index.html", "", 100)
+	system.Write(app.Destination+".gz", strings.Repeat("a", 4096), 0644)
+
+	packages := []*File{app, vendor, page}
+
+	tests := []struct {
+		name     string
+		budgets  []Budget
+		expected [][]string
+	}{
+		{
+			"within budget",
+			[]Budget{{Patterns: []string{"*.js"}, Limit: 4096}},
+			nil,
+		},
+		{
+			"single file over budget",
+			[]Budget{{Patterns: []string{"*.js"}, Limit: 1024}},
+			[][]string{{"js/app.a1b2.js"}},
+		},
+		{
+			"full path pattern",
+			[]Budget{{Patterns: []string{"js/vendor.js", "index.html"}, Limit: 256}},
+			[][]string{{"js/vendor.c3d4.js"}},
+		},
+		{
+			"total over budget",
+			[]Budget{{Patterns: []string{"*.js", "*.html"}, Limit: 2600, Total: true}},
+			[][]string{{"js/app.a1b2.js", "js/vendor.c3d4.js", "index.html"}},
+		},
+		{
+			"gzipped within budget",
+			[]Budget{{Patterns: []string{"*.js"}, Limit: 1024, Gzip: true}},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+
+		options.Budgets = test.budgets
+		offenders := CheckBudgets(options, packages)
+
+		var result [][]string
+		for _, offender := range offenders {
+			result = append(result, offender.Files)
+		}
+
+		if !slices.EqualFunc(result, test.expected, slices.Equal) {
+			t.Errorf("%s: CheckBudgets() = %v, expected %v", test.name, result, test.expected)
+		}
+
+	}
+
+}
@@ -0,0 +1,184 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/mateussouzaweb/compactor/src/errors"
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Builder struct
+// Holds the files index, packages, registered plugins and options of a project
+// Each builder has its own state, so multiple builds can run in the same process
+type Builder struct {
+	Options     *Options
+	files       []*File
+	packages    []*File
+	plugins     []*Plugin
+	initialized map[*Plugin]bool
+	tools       map[string]ToolStatus
+	exported    map[string][]string
+	temporary   []string
+}
+
+// NewBuilder creates a new builder with the given options
+// Options are bound to the builder, so plugins can reach its index
+func NewBuilder(options *Options) *Builder {
+
+	builder := &Builder{
+		Options:     options,
+		initialized: make(map[*Plugin]bool),
+		tools:       make(map[string]ToolStatus),
+		exported:    make(map[string][]string),
+	}
+
+	if options != nil {
+		options.builder = builder
+	}
+
+	return builder
+}
+
+// Default builder
+// Holds the plugins registered with the package level functions
+var Default = NewBuilder(nil)
+
+// Builder retrieves the builder bound to the options
+// Options without builder are bound to a new builder with the plugins registered on the default builder
+func (o *Options) Builder() *Builder {
+
+	if o.builder == nil {
+		builder := NewBuilder(o)
+		builder.plugins = slices.Clone(Default.plugins)
+	}
+
+	return o.builder
+}
+
+// prepare creates the working folders for filesystem sources and outputs
+// External tools only work with files on disk, so sources are mirrored into the source folder
+func (b *Builder) prepare() error {
+
+	options := b.Options
+
+	if options.Source.FS != nil {
+
+		if options.Source.Path == "" {
+			path, err := system.TemporaryDirectory("compactor-source")
+			if err != nil {
+				return err
+			}
+
+			options.Source.Path = path
+			b.temporary = append(b.temporary, path)
+		}
+
+		err := system.Extract(options.Source.FS, options.Source.Path)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	if options.Destination.Output != nil && options.Destination.Path == "" {
+
+		path, err := system.TemporaryDirectory("compactor-destination")
+		if err != nil {
+			return err
+		}
+
+		options.Destination.Path = path
+		b.temporary = append(b.temporary, path)
+
+	}
+
+	return nil
+}
+
+// export writes the final outputs of the file into the destination output
+// Outputs from previous processing that no longer exist, like old hashed names, are removed
+func (b *Builder) export(file *File) error {
+
+	output := b.Options.Destination.Output
+	if output == nil {
+		return nil
+	}
+
+	var names []string
+
+	for _, path := range Outputs(file) {
+
+		name := filepath.ToSlash(system.Relative(b.Options.Destination.Path, path))
+		names = append(names, name)
+
+		perm, err := system.Permissions(path)
+		if err != nil {
+			return err
+		}
+
+		reader, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		err = output.Write(name, reader, perm)
+		reader.Close()
+
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for _, name := range b.exported[file.Path] {
+		if !slices.Contains(names, name) {
+			err := output.Remove(name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	b.exported[file.Path] = names
+
+	return nil
+}
+
+// Build indexes the source files and process every package
+// Package errors are collected, so one failure does not stop the build
+func (b *Builder) Build(ctx context.Context) error {
+
+	err := b.prepare()
+	if err != nil {
+		return err
+	}
+
+	err = b.IndexFiles(b.Options.Source.Path)
+	if err != nil {
+		return err
+	}
+
+	var failures error
+
+	for _, file := range b.FindPackages() {
+
+		errors.Join(&failures, func() error {
+			err := b.Process(ctx, file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file.Location, err)
+			}
+			return nil
+		})
+
+		if ctx.Err() != nil {
+			break
+		}
+
+	}
+
+	return failures
+}
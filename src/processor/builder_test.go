@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// countingPlugin creates a plugin that copies files and counts its init and shutdown calls
+func countingPlugin(inits *int, shutdowns *int) *Plugin {
+	return &Plugin{
+		Namespace: "counting",
+		Init: func(options *Options) error {
+			*inits++
+			return nil
+		},
+		Shutdown: func(options *Options) error {
+			*shutdowns++
+			return nil
+		},
+		Resolve: func(options *Options, file *File) (string, error) {
+			return options.ToDestination(file.Path), nil
+		},
+		Related: func(options *Options, file *File) ([]Related, error) {
+			return nil, nil
+		},
+		Transform: func(ctx context.Context, options *Options, file *File) error {
+			return system.Copy(file.Path, file.Destination)
+		},
+		Optimize: func(ctx context.Context, options *Options, file *File) error {
+			return nil
+		},
+	}
+}
+
+func TestOptionsBuilder(t *testing.T) {
+
+	plugin := &Plugin{Namespace: "default"}
+	Default.AddPlugin(plugin)
+	defer Default.RemovePlugin("default")
+
+	first := &Options{}
+	second := &Options{}
+
+	if first.Builder() == Default || first.Builder() == second.Builder() {
+		t.Fatalf("expected a private builder for each options")
+	}
+	if first.Builder() != first.Builder() {
+		t.Errorf("expected the same builder on each call")
+	}
+	if Default.Options != nil {
+		t.Errorf("expected default builder options to be kept unchanged")
+	}
+	if first.Builder().GetPlugins()[0] != plugin {
+		t.Errorf("expected private builder with the default builder plugins")
+	}
+
+	first.Builder().RemovePlugin("default")
+	if len(Default.GetPlugins()) == 0 {
+		t.Errorf("expected private builder changes to not affect the default builder")
+	}
+
+	options := &Options{}
+	builder := NewBuilder(options)
+	if options.Builder() != builder {
+		t.Errorf("expected options bound to the builder")
+	}
+
+}
+
+func TestPluginInitialization(t *testing.T) {
+
+	var inits, shutdowns int
+	plugin := countingPlugin(&inits, &shutdowns)
+
+	for range 2 {
+
+		root := t.TempDir()
+		options := &Options{
+			Source:      Source{Path: filepath.Join(root, "src")},
+			Destination: Destination{Path: filepath.Join(root, "dist")},
+		}
+
+		system.EnsureDirectory(filepath.Join(options.Source.Path, "a.txt"))
+		system.Write(filepath.Join(options.Source.Path, "a.txt"), "a", 0644)
+		system.Write(filepath.Join(options.Source.Path, "b.txt"), "b", 0644)
+
+		builder := NewBuilder(options)
+		builder.AddPlugin(plugin)
+
+		err := builder.Build(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = builder.Shutdown()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	}
+
+	if inits != 2 || shutdowns != 2 {
+		t.Errorf("expected plugin initialized and shutdown once per builder, got %d inits and %d shutdowns", inits, shutdowns)
+	}
+
+}
+
+func TestPackageFunctions(t *testing.T) {
+
+	var inits, shutdowns int
+	Default.AddPlugin(countingPlugin(&inits, &shutdowns))
+	defer Default.RemovePlugin("counting")
+
+	root := t.TempDir()
+	options := &Options{
+		Source:      Source{Path: filepath.Join(root, "src")},
+		Destination: Destination{Path: filepath.Join(root, "dist")},
+	}
+
+	path := filepath.Join(options.Source.Path, "a.txt")
+	system.EnsureDirectory(path)
+	system.Write(path, "a", 0644)
+
+	err := IndexFiles(options, options.Source.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if GetFile(options, path).Path != path || len(GetFiles(options)) != 1 {
+		t.Errorf("expected indexed files available with the same options")
+	}
+	if len(FindPackages(options)) != 1 || len(FindOrphans(options)) != 1 || len(FindCycles(options)) != 0 {
+		t.Errorf("expected package and graph detection with the same options")
+	}
+
+	RemoveFile(options, path)
+	if GetFile(options, path).Exists {
+		t.Errorf("expected file removed with the same options")
+	}
+
+}
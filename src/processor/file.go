@@ -1,7 +1,10 @@
 package processor
 
 import (
+	"encoding/json"
 	"io/fs"
+
+	"github.com/mateussouzaweb/compactor/src/system"
 )
 
 // Related struct
@@ -23,16 +26,68 @@ type File struct {
 	File        string      `json:"file"`        // File name with extension
 	Name        string      `json:"name"`        // File name
 	Extension   string      `json:"extension"`   // File Extension
-	Content     string      `json:"content"`     // File content
+	Binary      bool        `json:"binary"`      // Binary content flag
 	Permission  fs.FileMode `json:"permission"`  // File permissions
 	Exists      bool        `json:"exists"`      // File exists flag
 	Checksum    []string    `json:"-"`           // Checksum history
+	Hash        string      `json:"-"`           // Hash on destination name
 	Related     []Related   `json:"-"`           // Related items
+	content     *string     // Lazy loaded content
+}
+
+// Content retrieves the file content, loading it from disk on the first use
+// Binary files have empty content, so they are never loaded for text scanning
+// Plugins that need binary content should stream it from disk instead
+func (f *File) Content() string {
+
+	if f.Binary {
+		return ""
+	}
+
+	if f.content == nil {
+		content, err := system.Read(f.Path)
+		if err != nil {
+			content = ""
+		}
+		f.content = &content
+	}
+
+	return *f.content
+}
+
+// Reset drops the loaded content, so it is read again from disk on the next use
+func (f *File) Reset() {
+	f.content = nil
+}
+
+// MarshalJSON encodes the file with the content of text files
+func (f *File) MarshalJSON() ([]byte, error) {
+
+	type file File
+	content := ""
+	if f.Exists {
+		content = f.Content()
+	}
+
+	return json.Marshal(struct {
+		*file
+		Content string `json:"content"`
+	}{
+		file:    (*file)(f),
+		Content: content,
+	})
 }
 
 // FindRelated retrieve the related paths of the item recursively
 func (f *File) FindRelated(onlyDependencies bool) []Related {
 
+	visited := map[string]bool{f.Path: true}
+	return f.findRelated(onlyDependencies, visited)
+}
+
+// findRelated retrieve the related paths of the item recursively, ignoring visited files
+func (f *File) findRelated(onlyDependencies bool, visited map[string]bool) []Related {
+
 	var found []Related
 	var related []Related
 	existing := make(map[string]bool)
@@ -44,8 +99,14 @@ func (f *File) FindRelated(onlyDependencies bool) []Related {
 
 		found = append(found, related)
 
+		if _, ok := visited[related.File.Path]; ok {
+			continue
+		}
+
+		visited[related.File.Path] = true
+
 		if len(related.File.Related) > 0 {
-			fromRelated := related.File.FindRelated(onlyDependencies)
+			fromRelated := related.File.findRelated(onlyDependencies, visited)
 			if len(fromRelated) > 0 {
 				found = append(found, fromRelated...)
 			}
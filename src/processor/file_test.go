@@ -0,0 +1,30 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+func TestFileContent(t *testing.T) {
+
+	root := t.TempDir()
+	builder := NewBuilder(&Options{})
+
+	system.Write(filepath.Join(root, "style.css"), "body{}", 0644)
+	system.Write(filepath.Join(root, "image.png"), "PNG\x00data", 0644)
+	builder.AppendFile(filepath.Join(root, "style.css"), root)
+	builder.AppendFile(filepath.Join(root, "image.png"), root)
+
+	text := builder.GetFile(filepath.Join(root, "style.css"))
+	if text.Binary || text.Content() != "body{}" {
+		t.Errorf("expected text file content, got binary %v and %q", text.Binary, text.Content())
+	}
+
+	binary := builder.GetFile(filepath.Join(root, "image.png"))
+	if !binary.Binary || binary.Content() != "" {
+		t.Errorf("expected empty content for binary file, got binary %v and %q", binary.Binary, binary.Content())
+	}
+
+}
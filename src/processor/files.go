@@ -1,24 +1,22 @@
 package processor
 
 import (
+	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/mateussouzaweb/compactor/src/system"
 )
 
-// File index list
-// The index contains information of all source files
-var _files []*File
-
 // GetFiles retrieve the indexed files
-func GetFiles() []*File {
-	return _files
+func (b *Builder) GetFiles() []*File {
+	return b.files
 }
 
 // GetFile retrieves the file that match path on index
-func GetFile(path string) *File {
+func (b *Builder) GetFile(path string) *File {
 
-	for _, file := range _files {
+	for _, file := range b.files {
 		if file.Path == path {
 			return file
 		}
@@ -28,10 +26,10 @@ func GetFile(path string) *File {
 }
 
 // AppendFile appends file information to index from its path
-func AppendFile(path string, root string) error {
+func (b *Builder) AppendFile(path string, root string) error {
 
 	location := system.Clean(path, root)
-	content, checksum, perm := system.Info(path)
+	checksum, binary, perm := system.Info(path)
 
 	file := File{
 		Path:        path,
@@ -42,30 +40,31 @@ func AppendFile(path string, root string) error {
 		File:        system.File(location),
 		Name:        system.Name(location),
 		Extension:   system.Extension(location),
-		Content:     content,
+		Binary:      binary,
 		Permission:  perm,
 		Exists:      system.Exist(path),
 		Checksum:    []string{checksum},
 	}
 
-	_files = append(_files, &file)
+	b.files = append(b.files, &file)
 
 	return nil
 }
 
 // UpdateFile updates file information on index if matches path
-func UpdateFile(path string) error {
+func (b *Builder) UpdateFile(path string) error {
 
-	for _, file := range _files {
+	for _, file := range b.files {
 
 		if file.Path != path {
 			continue
 		}
 
 		exists := system.Exist(file.Path)
-		content, checksum, perm := system.Info(file.Path)
+		checksum, binary, perm := system.Info(file.Path)
 
-		file.Content = content
+		file.Reset()
+		file.Binary = binary
 		file.Permission = perm
 		file.Exists = exists
 
@@ -82,15 +81,15 @@ func UpdateFile(path string) error {
 }
 
 // RemoveFile removes the file information from index if match path
-func RemoveFile(path string) {
+func (b *Builder) RemoveFile(path string) {
 
-	for _, file := range _files {
+	for _, file := range b.files {
 
 		if file.Path != path {
 			continue
 		}
 
-		file.Content = ""
+		file.Reset()
 		file.Exists = false
 
 		break
@@ -98,8 +97,10 @@ func RemoveFile(path string) {
 
 }
 
-// IndexFile index the root path files to the index, resolve related and determine destination
-func IndexFiles(options *Options, root string) error {
+// IndexFiles index the root path files to the index, resolve related and determine destination
+func (b *Builder) IndexFiles(root string) error {
+
+	options := b.Options
 
 	// First walks on path and add files to the index
 	paths, err := system.List(root)
@@ -107,33 +108,87 @@ func IndexFiles(options *Options, root string) error {
 		return err
 	}
 
+	listed := make(map[string]bool)
 	for _, path := range paths {
-		if GetFile(path).Path == "" {
-			AppendFile(path, root)
+		listed[path] = true
+		if b.GetFile(path).Path == "" {
+			b.AppendFile(path, root)
 		} else {
-			UpdateFile(path)
+			b.UpdateFile(path)
 		}
 	}
 
-	// With the updated index, resolve each file to discovery the final destination path
-	// We also detect the list of related files that the file have
-	for _, file := range _files {
+	// Indexed files removed from root are marked as not existing, so their outputs are deleted
+	for _, file := range b.files {
+		if file.Exists && !listed[file.Path] && strings.HasPrefix(file.Path, root+string(filepath.Separator)) {
+			b.RemoveFile(file.Path)
+		}
+	}
 
-		plugin := GetPlugin(file.Extension)
-		destination, err := plugin.Resolve(options, file)
+	// With the updated index, detect the list of related files that each file have
+	for _, file := range b.files {
+
+		plugin := b.GetPlugin(file.Extension)
+		related, err := plugin.Related(options, file)
 		if err != nil {
 			return err
 		}
 
-		related, err := plugin.Related(options, file)
+		file.Related = related
+
+	}
+
+	// Then resolve each file to discovery the final destination path
+	// Related files are detected first because hash can use the dependencies content
+	// Removed files keep the last destination, so the previous outputs can be found
+	for _, file := range b.files {
+
+		if !file.Exists && file.Destination != "" {
+			continue
+		}
+
+		plugin := b.GetPlugin(file.Extension)
+		destination, err := plugin.Resolve(options, file)
 		if err != nil {
 			return err
 		}
 
 		file.Destination = destination
-		file.Related = related
+
+		// Pre-compressed copies are generated dependencies of any plugin
+		file.Related = append(file.Related, PrecompressRelated(options, file)...)
 
 	}
 
 	return nil
 }
+
+// GetFiles retrieve the files indexed with the builder bound to the options
+func GetFiles(options *Options) []*File {
+	return options.Builder().GetFiles()
+}
+
+// GetFile retrieves the file that match path with the builder bound to the options
+func GetFile(options *Options, path string) *File {
+	return options.Builder().GetFile(path)
+}
+
+// AppendFile appends file information from its path with the builder bound to the options
+func AppendFile(options *Options, path string, root string) error {
+	return options.Builder().AppendFile(path, root)
+}
+
+// UpdateFile updates file information if matches path with the builder bound to the options
+func UpdateFile(options *Options, path string) error {
+	return options.Builder().UpdateFile(path)
+}
+
+// RemoveFile removes the file information if match path with the builder bound to the options
+func RemoveFile(options *Options, path string) {
+	options.Builder().RemoveFile(path)
+}
+
+// IndexFiles index the root path files with the builder bound to the options
+func IndexFiles(options *Options, root string) error {
+	return options.Builder().IndexFiles(root)
+}
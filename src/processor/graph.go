@@ -0,0 +1,235 @@
+package processor
+
+import (
+	"slices"
+	"strings"
+)
+
+// Edge struct
+type Edge struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Type       string `json:"type"`
+	Dependency bool   `json:"dependency"`
+	Generated  bool   `json:"generated"`
+}
+
+// Graph struct
+type Graph struct {
+	Files    []string   `json:"files"`
+	Packages []string   `json:"packages"`
+	Edges    []Edge     `json:"edges"`
+	Orphans  []string   `json:"orphans"`
+	Cycles   [][]string `json:"cycles"`
+}
+
+// IsGenerated return if the related file is generated by the plugin instead of existing on index
+func (b *Builder) IsGenerated(related Related) bool {
+	return b.GetFile(related.File.Path).Path == ""
+}
+
+// FindOrphans retrieves the indexed files that are not referenced by any other file
+// HTML pages are the entry points of projects, so they are never considered orphans
+func (b *Builder) FindOrphans() []*File {
+
+	var orphans []*File
+	referenced := make(map[string]bool)
+
+	for _, file := range b.files {
+		for _, related := range file.Related {
+			if related.File.Path != file.Path {
+				referenced[related.File.Path] = true
+			}
+		}
+	}
+
+	for _, file := range b.files {
+
+		if !file.Exists || referenced[file.Path] {
+			continue
+		}
+		if file.Extension == ".html" || file.Extension == ".htm" {
+			continue
+		}
+
+		orphans = append(orphans, file)
+
+	}
+
+	return orphans
+}
+
+// FindCycles retrieves the circular references between indexed files
+func (b *Builder) FindCycles() [][]*File {
+
+	var cycles [][]*File
+	var stack []*File
+	var visit func(file *File)
+
+	visited := make(map[string]bool)
+	active := make(map[string]bool)
+	found := make(map[string]bool)
+
+	visit = func(file *File) {
+
+		visited[file.Path] = true
+		active[file.Path] = true
+		stack = append(stack, file)
+
+		for _, related := range file.Related {
+
+			if related.File.Path == "" || b.IsGenerated(related) {
+				continue
+			}
+
+			if active[related.File.Path] {
+
+				// Extract the cycle from the current stack
+				index := slices.IndexFunc(stack, func(item *File) bool {
+					return item.Path == related.File.Path
+				})
+
+				cycle := slices.Clone(stack[index:])
+				cycle = append(cycle, related.File)
+
+				// Each cycle is reported only once, no matter the start point
+				var paths []string
+				for _, item := range stack[index:] {
+					paths = append(paths, item.Path)
+				}
+
+				slices.Sort(paths)
+				key := strings.Join(paths, "|")
+
+				if !found[key] {
+					found[key] = true
+					cycles = append(cycles, cycle)
+				}
+
+				continue
+			}
+
+			if !visited[related.File.Path] {
+				visit(related.File)
+			}
+
+		}
+
+		stack = stack[:len(stack)-1]
+		active[file.Path] = false
+
+	}
+
+	for _, file := range b.files {
+		if !visited[file.Path] {
+			visit(file)
+		}
+	}
+
+	return cycles
+}
+
+// FindDependents retrieves the packages that depends on the given path, directly or not
+func (b *Builder) FindDependents(path string) []*File {
+
+	options := b.Options
+
+	var dependents []*File
+	source := options.ToSource(path)
+
+	for _, file := range b.packages {
+
+		if file.Path == source {
+			continue
+		}
+
+		for _, related := range file.FindRelated(false) {
+			if related.File.Path == source {
+				dependents = append(dependents, file)
+				break
+			}
+		}
+
+	}
+
+	return dependents
+}
+
+// BuildGraph creates the graph representation of the index with clean paths
+func (b *Builder) BuildGraph() *Graph {
+
+	options := b.Options
+
+	graph := &Graph{
+		Files:    []string{},
+		Packages: []string{},
+		Edges:    []Edge{},
+		Orphans:  []string{},
+		Cycles:   [][]string{},
+	}
+
+	for _, file := range b.files {
+
+		graph.Files = append(graph.Files, options.CleanPath(file.Path))
+
+		for _, related := range file.Related {
+
+			if related.File.Path == "" {
+				continue
+			}
+
+			graph.Edges = append(graph.Edges, Edge{
+				From:       options.CleanPath(file.Path),
+				To:         options.CleanPath(related.File.Path),
+				Type:       related.Type,
+				Dependency: related.Dependency,
+				Generated:  b.IsGenerated(related),
+			})
+
+		}
+
+	}
+
+	for _, file := range b.packages {
+		graph.Packages = append(graph.Packages, options.CleanPath(file.Path))
+	}
+
+	for _, file := range b.FindOrphans() {
+		graph.Orphans = append(graph.Orphans, options.CleanPath(file.Path))
+	}
+
+	for _, cycle := range b.FindCycles() {
+		var paths []string
+		for _, file := range cycle {
+			paths = append(paths, options.CleanPath(file.Path))
+		}
+		graph.Cycles = append(graph.Cycles, paths)
+	}
+
+	return graph
+}
+
+// IsGenerated return if the related file is generated by the plugin with the builder bound to the options
+func IsGenerated(options *Options, related Related) bool {
+	return options.Builder().IsGenerated(related)
+}
+
+// FindOrphans retrieves the files that are not referenced by any other file with the builder bound to the options
+func FindOrphans(options *Options) []*File {
+	return options.Builder().FindOrphans()
+}
+
+// FindCycles retrieves the circular references between files with the builder bound to the options
+func FindCycles(options *Options) [][]*File {
+	return options.Builder().FindCycles()
+}
+
+// FindDependents retrieves the packages that depends on the given path with the builder bound to the options
+func FindDependents(options *Options, path string) []*File {
+	return options.Builder().FindDependents(path)
+}
+
+// BuildGraph creates the graph representation with the builder bound to the options
+func BuildGraph(options *Options) *Graph {
+	return options.Builder().BuildGraph()
+}
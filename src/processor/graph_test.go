@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"slices"
+	"testing"
+)
+
+// graphBuilder creates a builder with files related as a -> b -> c -> a and page -> a
+func graphBuilder() (*Builder, map[string]*File) {
+
+	options := &Options{
+		Source: Source{Path: "/src"},
+	}
+
+	files := map[string]*File{}
+	for _, name := range []string{"page.html", "a.js", "b.js", "c.js", "d.js"} {
+		files[name] = &File{Path: "/src/" + name, Exists: true}
+	}
+
+	files["page.html"].Related = []Related{{Type: "script", Dependency: false, Path: "a.js", File: files["a.js"]}}
+	files["a.js"].Related = []Related{{Type: "import", Dependency: true, Path: "b.js", File: files["b.js"]}}
+	files["b.js"].Related = []Related{{Type: "import", Dependency: true, Path: "c.js", File: files["c.js"]}}
+	files["c.js"].Related = []Related{{Type: "import", Dependency: true, Path: "a.js", File: files["a.js"]}}
+
+	builder := NewBuilder(options)
+	for _, name := range []string{"page.html", "a.js", "b.js", "c.js", "d.js"} {
+		builder.files = append(builder.files, files[name])
+	}
+
+	builder.packages = []*File{files["page.html"], files["a.js"], files["d.js"]}
+
+	return builder, files
+}
+
+func TestFindCycles(t *testing.T) {
+
+	builder, _ := graphBuilder()
+	cycles := builder.FindCycles()
+
+	if len(cycles) != 1 {
+		t.Fatalf("FindCycles() found %d cycles, expected 1", len(cycles))
+	}
+
+	var paths []string
+	for _, file := range cycles[0] {
+		paths = append(paths, file.Path)
+	}
+
+	expected := []string{"/src/a.js", "/src/b.js", "/src/c.js", "/src/a.js"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("FindCycles() = %v, expected %v", paths, expected)
+	}
+
+	builder.files[3].Related = nil
+	if cycles := builder.FindCycles(); len(cycles) != 0 {
+		t.Errorf("FindCycles() found %d cycles without circular reference, expected 0", len(cycles))
+	}
+
+}
+
+func TestFindDependents(t *testing.T) {
+
+	builder, _ := graphBuilder()
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"c.js", []string{"/src/page.html", "/src/a.js"}},
+		{"a.js", []string{"/src/page.html"}},
+		{"d.js", nil},
+		{"missing.js", nil},
+	}
+
+	for _, test := range tests {
+
+		var paths []string
+		for _, file := range builder.FindDependents(test.path) {
+			paths = append(paths, file.Path)
+		}
+
+		if !slices.Equal(paths, test.expected) {
+			t.Errorf("FindDependents(%q) = %v, expected %v", test.path, paths, test.expected)
+		}
+
+	}
+
+}
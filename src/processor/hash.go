@@ -0,0 +1,120 @@
+package processor
+
+import (
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Suffixes of generated files that are named after the destination file
+var HashSuffixes = []string{".map", ".webp", ".headers"}
+
+// SourceHash computes the hash from the source content of the file and its dependencies
+// Only dependencies are included, so linked files like images do not change the hash
+func SourceHash(options *Options, file *File) string {
+
+	files := []string{file.Path}
+	for _, related := range file.FindRelated(true) {
+		if related.File.Exists {
+			files = append(files, related.File.Path)
+		}
+	}
+
+	hash, err := system.HashFiles(files, options.Destination.Algorithm, options.Destination.Length)
+	if err != nil {
+		return system.Hash("", options.Destination.Algorithm, options.Destination.Length)
+	}
+
+	return hash
+}
+
+// HashDestination returns the destination path with the file hash on its name
+// Until processed, the hash comes from the source content, then from the final output content
+func HashDestination(options *Options, file *File, destination string) string {
+
+	if file.Hash == "" {
+		file.Hash = SourceHash(options, file)
+	}
+
+	return options.ToHashed(destination, file.Hash)
+}
+
+// UpdateHash updates the file hash from the final output content and renames the destination when changed
+func UpdateHash(options *Options, file *File) error {
+
+	if file.Hash == "" || !system.Exist(file.Destination) {
+		return nil
+	}
+
+	hash, err := system.HashFiles(
+		[]string{file.Destination},
+		options.Destination.Algorithm,
+		options.Destination.Length,
+	)
+	if err != nil {
+		return err
+	}
+
+	if hash == file.Hash {
+		return nil
+	}
+
+	previous := file.Destination
+	destination := options.ToNonHashed(previous, file.Hash)
+	destination = options.ToHashed(destination, hash)
+
+	if destination != previous {
+
+		err = system.Rename(previous, destination)
+		if err != nil {
+			return err
+		}
+
+		for _, suffix := range HashSuffixes {
+			if system.Exist(previous + suffix) {
+				err = system.Rename(previous+suffix, destination+suffix)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Generated related files named after the destination follow the rename
+			for _, item := range file.Related {
+				if item.File != nil && item.File.Destination == previous+suffix && options.Builder().IsGenerated(item) {
+					item.File.Destination = destination + suffix
+				}
+			}
+		}
+
+		// Pre-compressed copies from the previous name are stale
+		stale := []string{previous}
+		for _, suffix := range HashSuffixes {
+			stale = append(stale, previous+suffix)
+		}
+
+		for _, path := range stale {
+			for _, extension := range PrecompressFormats {
+				if system.Exist(path + extension) {
+					err = system.Delete(path + extension)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+	}
+
+	file.Destination = destination
+	file.Hash = hash
+
+	// Pre-compressed related files are named after the final destination
+	var related []Related
+	for _, item := range file.Related {
+		if item.Type != "compressed" {
+			related = append(related, item)
+		}
+	}
+
+	file.Related = append(related, PrecompressRelated(options, file)...)
+
+	return nil
+}
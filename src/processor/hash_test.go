@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+func TestUpdateHashAndDelete(t *testing.T) {
+
+	root := t.TempDir()
+	options := &Options{
+		Source: Source{Path: filepath.Join(root, "src")},
+		Destination: Destination{
+			Path:      filepath.Join(root, "dist"),
+			Hashed:    true,
+			Algorithm: "md5",
+			Length:    8,
+		},
+		Precompress: Precompress{
+			Enabled:   true,
+			Formats:   []string{"gzip"},
+			Threshold: 0,
+		},
+	}
+
+	source := filepath.Join(options.Source.Path, "app.js")
+	file := &File{Path: source, File: "app.js", Hash: "00000000", Permission: 0644}
+	file.Destination = options.ToHashed(filepath.Join(options.Destination.Path, "app.js"), file.Hash)
+	file.Related = PrecompressRelated(options, file)
+	file.Related = append(file.Related, Related{
+		Type:       "headers",
+		Dependency: true,
+		File:       &File{Path: source + ".headers", Destination: file.Destination + ".headers"},
+	})
+
+	// Previous build outputs with the source hash name
+	os.MkdirAll(options.Destination.Path, 0755)
+	system.Write(file.Destination, strings.Repeat("content ", 100), 0644)
+	system.Write(file.Destination+".map", "{}", 0644)
+	system.Write(file.Destination+".gz", "stale", 0644)
+
+	previous := file.Destination
+	err := UpdateHash(options, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.Destination == previous || !system.Exist(file.Destination) || !system.Exist(file.Destination+".map") {
+		t.Fatalf("expected destination renamed from %s, got %s", previous, file.Destination)
+	}
+	if system.Exist(previous) || system.Exist(previous+".gz") {
+		t.Errorf("expected stale outputs of %s removed", previous)
+	}
+
+	for _, related := range file.Related {
+		if related.Type == "compressed" && related.File.Destination != file.Destination+".gz" {
+			t.Errorf("compressed related = %s, expected %s", related.File.Destination, file.Destination+".gz")
+		}
+		if related.Type == "headers" && related.File.Destination != file.Destination+".headers" {
+			t.Errorf("headers related = %s, expected %s", related.File.Destination, file.Destination+".headers")
+		}
+	}
+
+	err = CreatePrecompressed(options, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := Outputs(file)
+	if len(outputs) != 4 {
+		t.Errorf("Outputs() = %v, expected destination, map and their compressed copies", outputs)
+	}
+
+	err = Delete(options, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := os.ReadDir(options.Destination.Path)
+	if len(entries) != 0 {
+		t.Errorf("expected every output removed, found %d files", len(entries))
+	}
+
+}
@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// ShouldForceInline return if the reference path explicitly asks to be inlined
+func ShouldForceInline(path string) bool {
+	return strings.Contains(path, "?inline") || strings.Contains(path, "&inline")
+}
+
+// InlineContent retrieves the content of the file to be inlined on the referencing file
+// Final output is preferred and files above the size threshold are not inlined unless forced
+func InlineContent(options *Options, file *File, force bool) (string, bool) {
+
+	if !file.Exists {
+		return "", false
+	}
+	if !force && !options.ShouldInline(file.Path) {
+		return "", false
+	}
+
+	path := file.Destination
+	if !system.Exist(path) {
+		path = file.Path
+	}
+
+	if !force && system.Size(path) > options.Inline.Threshold {
+		return "", false
+	}
+
+	content, err := system.Read(path)
+	if err != nil {
+		return "", false
+	}
+
+	return content, true
+}
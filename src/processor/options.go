@@ -1,24 +1,36 @@
 package processor
 
 import (
+	"io/fs"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mateussouzaweb/compactor/src/system"
 )
 
 // Source struct
+// When FS is set, its files are mirrored into Path, or a temporary folder, before indexing
+// Files on Path that do not exist on FS are removed on each build
 type Source struct {
 	Path    string
+	FS      fs.FS
 	Include []string
 	Exclude []string
 }
 
 // Destination struct
+// When Output is set, final files are also exported to it from Path, or a temporary folder
 type Destination struct {
-	Path   string
-	Hashed bool
+	Path      string
+	Output    Output
+	Hashed    bool
+	Include   []string
+	Exclude   []string
+	Algorithm string
+	Length    int
+	Query     bool
 }
 
 // Compress struct
@@ -42,13 +54,79 @@ type Progressive struct {
 	Exclude []string
 }
 
+// Precompress struct
+type Precompress struct {
+	Enabled   bool
+	Include   []string
+	Exclude   []string
+	Formats   []string
+	Threshold int64
+}
+
+// Integrity struct
+type Integrity struct {
+	Enabled     bool
+	Include     []string
+	Exclude     []string
+	CrossOrigin string
+}
+
+// ContentPolicy struct
+type ContentPolicy struct {
+	Enabled bool
+	Include []string
+	Exclude []string
+	Output  string
+}
+
+// Critical struct
+type Critical struct {
+	Enabled bool
+	Include []string
+	Exclude []string
+}
+
+// Inline struct
+type Inline struct {
+	Enabled   bool
+	Include   []string
+	Exclude   []string
+	Threshold int64
+}
+
+// Budget struct
+// Total budgets compare the sum of every matching file against the limit
+type Budget struct {
+	Patterns []string
+	Limit    int64
+	Total    bool
+	Gzip     bool
+}
+
+// Tools struct
+type Tools struct {
+	NodeModules string
+	Path        map[string]string
+	Args        map[string][]string
+	Env         map[string][]string
+}
+
 // Options struct
 type Options struct {
-	Source      Source
-	Destination Destination
-	Compress    Compress
-	SourceMap   SourceMap
-	Progressive Progressive
+	Source        Source
+	Destination   Destination
+	Compress      Compress
+	SourceMap     SourceMap
+	Progressive   Progressive
+	Precompress   Precompress
+	Integrity     Integrity
+	ContentPolicy ContentPolicy
+	Critical      Critical
+	Inline        Inline
+	Tools         Tools
+	Budgets       []Budget
+	Timeout       time.Duration
+	builder       *Builder
 }
 
 // CleanPath return the clean path, without source and destination path
@@ -81,6 +159,28 @@ func (o *Options) MatchPatterns(file string, patterns []string) bool {
 	return false
 }
 
+// MatchNames return if file match one of the given patterns
+// Unlike MatchPatterns, patterns without folders also match the file name in any folder
+func (o *Options) MatchNames(file string, patterns []string) bool {
+
+	if o.MatchPatterns(file, patterns) {
+		return true
+	}
+
+	name := path.Base(o.CleanPath(file))
+
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			result, _ := path.Match(pattern, name)
+			if result {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // ShouldInclude return if path should be included on the bundle
 func (o *Options) ShouldInclude(path string) bool {
 
@@ -95,6 +195,24 @@ func (o *Options) ShouldInclude(path string) bool {
 	return true
 }
 
+// ShouldHash return if destination file name should have the hash for given path
+// Plugins that are not hashed by default only have hash when the path is explicitly included
+func (o *Options) ShouldHash(path string, byDefault bool) bool {
+
+	if !o.Destination.Hashed {
+		return false
+	}
+
+	if len(o.Destination.Exclude) != 0 && o.MatchNames(path, o.Destination.Exclude) {
+		return false
+	}
+	if !byDefault && !o.MatchNames(path, o.Destination.Include) {
+		return false
+	}
+
+	return true
+}
+
 // ShouldCompress return if compress should be enabled for given path
 func (o *Options) ShouldCompress(path string) bool {
 
@@ -146,6 +264,91 @@ func (o *Options) ShouldGenerateProgressive(path string) bool {
 	return true
 }
 
+// ShouldPrecompress return if pre-compressed copies should be generated for given path
+func (o *Options) ShouldPrecompress(path string) bool {
+
+	if !o.Precompress.Enabled {
+		return false
+	}
+
+	if len(o.Precompress.Exclude) != 0 && o.MatchPatterns(path, o.Precompress.Exclude) {
+		return false
+	}
+	if len(o.Precompress.Include) != 0 && !o.MatchPatterns(path, o.Precompress.Include) {
+		return false
+	}
+
+	return true
+}
+
+// ShouldGenerateIntegrity return if subresource integrity should be generated for given path
+func (o *Options) ShouldGenerateIntegrity(path string) bool {
+
+	if !o.Integrity.Enabled {
+		return false
+	}
+
+	if len(o.Integrity.Exclude) != 0 && o.MatchPatterns(path, o.Integrity.Exclude) {
+		return false
+	}
+	if len(o.Integrity.Include) != 0 && !o.MatchPatterns(path, o.Integrity.Include) {
+		return false
+	}
+
+	return true
+}
+
+// ShouldGenerateContentPolicy return if content security policy hashes should be generated for given path
+func (o *Options) ShouldGenerateContentPolicy(path string) bool {
+
+	if !o.ContentPolicy.Enabled {
+		return false
+	}
+
+	if len(o.ContentPolicy.Exclude) != 0 && o.MatchPatterns(path, o.ContentPolicy.Exclude) {
+		return false
+	}
+	if len(o.ContentPolicy.Include) != 0 && !o.MatchPatterns(path, o.ContentPolicy.Include) {
+		return false
+	}
+
+	return true
+}
+
+// ShouldInlineCritical return if critical CSS should be inlined for given path
+func (o *Options) ShouldInlineCritical(path string) bool {
+
+	if !o.Critical.Enabled {
+		return false
+	}
+
+	if len(o.Critical.Exclude) != 0 && o.MatchPatterns(path, o.Critical.Exclude) {
+		return false
+	}
+	if len(o.Critical.Include) != 0 && !o.MatchPatterns(path, o.Critical.Include) {
+		return false
+	}
+
+	return true
+}
+
+// ShouldInline return if small files should be inlined for given path
+func (o *Options) ShouldInline(path string) bool {
+
+	if !o.Inline.Enabled {
+		return false
+	}
+
+	if len(o.Inline.Exclude) != 0 && o.MatchPatterns(path, o.Inline.Exclude) {
+		return false
+	}
+	if len(o.Inline.Include) != 0 && !o.MatchPatterns(path, o.Inline.Include) {
+		return false
+	}
+
+	return true
+}
+
 // ToSource transform and return the full source path for given path
 func (o *Options) ToSource(path string) string {
 	return filepath.Join(o.Source.Path, o.CleanPath(path))
@@ -167,9 +370,10 @@ func (o *Options) ToExtension(path string, extension string) string {
 }
 
 // ToHashed return a file path converted to a hashed name to avoid caching
+// When query cache busting is enabled, the path is kept unchanged
 func (o *Options) ToHashed(path string, hash string) string {
 
-	if hash == "" || !o.Destination.Hashed {
+	if hash == "" || !o.Destination.Hashed || o.Destination.Query {
 		return path
 	}
 
@@ -183,7 +387,7 @@ func (o *Options) ToHashed(path string, hash string) string {
 // ToNonHashed return a file path converted back to a non hashed name
 func (o *Options) ToNonHashed(path string, hash string) string {
 
-	if hash == "" || !o.Destination.Hashed {
+	if hash == "" || !o.Destination.Hashed || o.Destination.Query {
 		return path
 	}
 
@@ -194,3 +398,13 @@ func (o *Options) ToNonHashed(path string, hash string) string {
 
 	return path
 }
+
+// ToVersioned return a reference path with the hash as query string when query cache busting is enabled
+func (o *Options) ToVersioned(path string, hash string) string {
+
+	if hash == "" || !o.Destination.Hashed || !o.Destination.Query {
+		return path
+	}
+
+	return path + "?v=" + hash
+}
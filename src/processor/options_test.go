@@ -0,0 +1,92 @@
+package processor
+
+import "testing"
+
+func TestMatchPatterns(t *testing.T) {
+
+	options := &Options{
+		Source:      Source{Path: "/project/src"},
+		Destination: Destination{Path: "/project/dist"},
+	}
+
+	tests := []struct {
+		file     string
+		patterns []string
+		expected bool
+	}{
+		{"/project/src/app.js", []string{"*.js"}, true},
+		{"/project/src/vendor/lib.js", []string{"*.js"}, false},
+		{"/project/src/vendor/lib.js", []string{"vendor/*.js"}, true},
+		{"/project/dist/vendor/lib.js", []string{"vendor/*"}, true},
+		{"/project/src/app.css", []string{"*.js", "*.css"}, true},
+		{"/project/src/app.css", []string{"*.js"}, false},
+		{"/project/src/app.js", []string{"[invalid"}, false},
+	}
+
+	for _, test := range tests {
+		result := options.MatchPatterns(test.file, test.patterns)
+		if result != test.expected {
+			t.Errorf("MatchPatterns(%q, %v) = %v, expected %v", test.file, test.patterns, result, test.expected)
+		}
+	}
+
+}
+
+func TestMatchNames(t *testing.T) {
+
+	options := &Options{
+		Source:      Source{Path: "/project/src"},
+		Destination: Destination{Path: "/project/dist"},
+	}
+
+	tests := []struct {
+		file     string
+		patterns []string
+		expected bool
+	}{
+		{"/project/src/app.js", []string{"*.js"}, true},
+		{"/project/src/vendor/lib.js", []string{"*.js"}, true},
+		{"/project/src/vendor/lib.js", []string{"vendor/*.js"}, true},
+		{"/project/src/other/lib.js", []string{"vendor/*.js"}, false},
+		{"/project/src/images/logo.png", []string{"*.jpg", "*.png"}, true},
+		{"/project/src/images/logo.png", []string{"*.jpg"}, false},
+	}
+
+	for _, test := range tests {
+		result := options.MatchNames(test.file, test.patterns)
+		if result != test.expected {
+			t.Errorf("MatchNames(%q, %v) = %v, expected %v", test.file, test.patterns, result, test.expected)
+		}
+	}
+
+}
+
+func TestShouldHash(t *testing.T) {
+
+	tests := []struct {
+		destination Destination
+		file        string
+		byDefault   bool
+		expected    bool
+	}{
+		{Destination{Hashed: false}, "/src/app.js", true, false},
+		{Destination{Hashed: true}, "/src/app.js", true, true},
+		{Destination{Hashed: true}, "/src/logo.png", false, false},
+		{Destination{Hashed: true, Include: []string{"*.png"}}, "/src/images/logo.png", false, true},
+		{Destination{Hashed: true, Include: []string{"*.png"}}, "/src/images/logo.jpg", false, false},
+		{Destination{Hashed: true, Exclude: []string{"*.js"}}, "/src/vendor/lib.js", true, false},
+		{Destination{Hashed: true, Exclude: []string{"vendor/*"}}, "/src/app.js", true, true},
+	}
+
+	for _, test := range tests {
+		options := &Options{
+			Source:      Source{Path: "/src"},
+			Destination: test.destination,
+		}
+		result := options.ShouldHash(test.file, test.byDefault)
+		if result != test.expected {
+			t.Errorf("ShouldHash(%q, %v) with %+v = %v, expected %v", test.file, test.byDefault, test.destination, result, test.expected)
+		}
+	}
+
+}
@@ -0,0 +1,345 @@
+package processor
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Output interface
+// Filesystem where the final files are exported, like disk, memory or zip archive
+// Paths are slash separated and relative to the output root, like fs.FS names
+type Output interface {
+	Write(path string, reader io.Reader, perm fs.FileMode) error
+	Remove(path string) error
+	Close() error
+}
+
+// DiskOutput struct
+type DiskOutput struct {
+	Root string
+}
+
+// NewDiskOutput creates the output that writes files into the root folder
+func NewDiskOutput(root string) *DiskOutput {
+	return &DiskOutput{
+		Root: root,
+	}
+}
+
+// Write streams the content into the file
+func (o *DiskOutput) Write(name string, reader io.Reader, perm fs.FileMode) error {
+
+	file := filepath.Join(o.Root, filepath.FromSlash(name))
+
+	err := system.EnsureDirectory(file)
+	if err != nil {
+		return err
+	}
+
+	return system.Stream(file, reader, perm)
+}
+
+// Remove deletes the file if exists
+func (o *DiskOutput) Remove(name string) error {
+
+	file := filepath.Join(o.Root, filepath.FromSlash(name))
+	if !system.Exist(file) {
+		return nil
+	}
+
+	return system.Delete(file)
+}
+
+// Close output
+func (o *DiskOutput) Close() error {
+	return nil
+}
+
+// MemoryOutput struct
+// Also implements fs.FS, so compiled files can be served directly from memory
+type MemoryOutput struct {
+	files map[string]*memoryFile
+	mutex sync.RWMutex
+}
+
+// memoryFile struct
+// Content is replaced on write, never modified, so opened files keep a consistent snapshot
+type memoryFile struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemoryOutput creates the output that keeps files in memory
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{
+		files: make(map[string]*memoryFile),
+	}
+}
+
+// Write reads the content into memory
+func (o *MemoryOutput) Write(name string, reader io.Reader, perm fs.FileMode) error {
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.files[path.Clean(name)] = &memoryFile{
+		data:    content,
+		mode:    perm,
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+// Remove deletes the file from memory
+func (o *MemoryOutput) Remove(name string) error {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	delete(o.files, path.Clean(name))
+
+	return nil
+}
+
+// Close output
+func (o *MemoryOutput) Close() error {
+	return nil
+}
+
+// Open opens the named file from memory
+// Folders are derived from the file names, so they only exist while having files
+func (o *MemoryOutput) Open(name string) (fs.File, error) {
+
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	if file, ok := o.files[name]; ok {
+		return &memoryReader{
+			Reader: bytes.NewReader(file.data),
+			info:   &memoryInfo{name: path.Base(name), size: int64(len(file.data)), mode: file.mode, modTime: file.modTime},
+		}, nil
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+
+	children := make(map[string]fs.FileInfo)
+	for key, file := range o.files {
+
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		child, _, nested := strings.Cut(strings.TrimPrefix(key, prefix), "/")
+		if nested {
+			children[child] = &memoryInfo{name: child, mode: fs.ModeDir | 0755}
+		} else {
+			children[child] = &memoryInfo{name: child, size: int64(len(file.data)), mode: file.mode, modTime: file.modTime}
+		}
+
+	}
+
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	var entries []fs.DirEntry
+	for _, child := range slices.Sorted(maps.Keys(children)) {
+		entries = append(entries, fs.FileInfoToDirEntry(children[child]))
+	}
+
+	return &memoryDirectory{
+		info:    &memoryInfo{name: path.Base(name), mode: fs.ModeDir | 0755},
+		entries: entries,
+	}, nil
+}
+
+// Files retrieves the sorted names of the files in memory
+func (o *MemoryOutput) Files() []string {
+
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	return slices.Sorted(maps.Keys(o.files))
+}
+
+// Read retrieves the content of the file from memory
+func (o *MemoryOutput) Read(name string) ([]byte, bool) {
+
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	file, ok := o.files[path.Clean(name)]
+	if !ok {
+		return nil, false
+	}
+
+	return file.data, true
+}
+
+// memoryInfo struct
+type memoryInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// Name retrieves the base name of the file
+func (i *memoryInfo) Name() string {
+	return i.name
+}
+
+// Size retrieves the length of the content
+func (i *memoryInfo) Size() int64 {
+	return i.size
+}
+
+// Mode retrieves the file mode bits
+func (i *memoryInfo) Mode() fs.FileMode {
+	return i.mode
+}
+
+// ModTime retrieves the time of the last write
+func (i *memoryInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+// IsDir return if is a folder
+func (i *memoryInfo) IsDir() bool {
+	return i.mode.IsDir()
+}
+
+// Sys has no underlying data source
+func (i *memoryInfo) Sys() any {
+	return nil
+}
+
+// memoryReader struct
+// Seekable reader of the file content, as required to serve files over HTTP
+type memoryReader struct {
+	*bytes.Reader
+	info *memoryInfo
+}
+
+// Stat retrieves the file information
+func (r *memoryReader) Stat() (fs.FileInfo, error) {
+	return r.info, nil
+}
+
+// Close file
+func (r *memoryReader) Close() error {
+	return nil
+}
+
+// memoryDirectory struct
+type memoryDirectory struct {
+	info    *memoryInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+// Stat retrieves the folder information
+func (d *memoryDirectory) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+// Close folder
+func (d *memoryDirectory) Close() error {
+	return nil
+}
+
+// Read fails because folders have no content
+func (d *memoryDirectory) Read(buffer []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir retrieves the next entries of the folder
+func (d *memoryDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
+
+	remaining := d.entries[d.offset:]
+	if count > 0 && len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count > 0 && count < len(remaining) {
+		remaining = remaining[:count]
+	}
+
+	d.offset += len(remaining)
+
+	return remaining, nil
+}
+
+// ZipOutput struct
+// Files are kept in memory and the archive is written on close, so updated files are not duplicated
+type ZipOutput struct {
+	MemoryOutput
+	writer io.Writer
+}
+
+// NewZipOutput creates the output that writes the zip archive into the writer
+func NewZipOutput(writer io.Writer) *ZipOutput {
+	return &ZipOutput{
+		MemoryOutput: MemoryOutput{
+			files: make(map[string]*memoryFile),
+		},
+		writer: writer,
+	}
+}
+
+// Close writes the zip archive with every file
+func (o *ZipOutput) Close() error {
+
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	archive := zip.NewWriter(o.writer)
+
+	for _, name := range slices.Sorted(maps.Keys(o.files)) {
+
+		file := o.files[name]
+		header := &zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: file.modTime,
+		}
+		header.SetMode(file.mode)
+
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		_, err = writer.Write(file.data)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return archive.Close()
+}
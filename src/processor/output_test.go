@@ -0,0 +1,196 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+func TestMemoryOutput(t *testing.T) {
+
+	output := NewMemoryOutput()
+	output.Write("index.html", strings.NewReader("<html></html>"), 0644)
+	output.Write("js/app.js", strings.NewReader("app()"), 0644)
+	output.Write("js/vendor/lib.js", strings.NewReader("lib()"), 0644)
+	output.Write("removed.txt", strings.NewReader("removed"), 0644)
+	output.Remove("removed.txt")
+
+	err := fstest.TestFS(output, "index.html", "js/app.js", "js/vendor/lib.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := output.Open("removed.txt"); err == nil {
+		t.Errorf("expected removed file to not exist")
+	}
+
+	// Files can be served over HTTP directly from memory
+	request := httptest.NewRequest(http.MethodGet, "/js/app.js", nil)
+	request.Header.Set("Range", "bytes=0-2")
+	response := httptest.NewRecorder()
+	http.ServeFileFS(response, request, output, "js/app.js")
+
+	body, _ := io.ReadAll(response.Body)
+	if response.Code != http.StatusPartialContent || string(body) != "app" {
+		t.Errorf("ServeFileFS() = %d %q, expected 206 \"app\"", response.Code, body)
+	}
+
+}
+
+// memoryBuild builds the source filesystem into a new memory output
+func memoryBuild(t *testing.T, source fstest.MapFS, timeout time.Duration, plugin *Plugin) (*Builder, *MemoryOutput) {
+
+	output := NewMemoryOutput()
+	builder := NewBuilder(&Options{
+		Source:      Source{FS: source},
+		Destination: Destination{Output: output},
+		Timeout:     timeout,
+	})
+	builder.AddPlugin(plugin)
+
+	t.Cleanup(func() {
+		builder.Shutdown()
+	})
+
+	return builder, output
+}
+
+func TestMemoryBuild(t *testing.T) {
+
+	var inits, shutdowns int
+	source := fstest.MapFS{
+		"index.html":    {Data: []byte("<html></html>"), Mode: 0644},
+		"js/app.js":     {Data: []byte("app()"), Mode: 0644},
+		"css/style.css": {Data: []byte("body{}"), Mode: 0644},
+	}
+
+	builder, output := memoryBuild(t, source, 0, countingPlugin(&inits, &shutdowns))
+
+	err := builder.Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "css/style.css,index.html,js/app.js"
+	if files := strings.Join(output.Files(), ","); files != expected {
+		t.Fatalf("Files() = %s, expected %s", files, expected)
+	}
+
+	// Rebuild with updated, added and removed source files
+	source["js/app.js"] = &fstest.MapFile{Data: []byte("app(2)"), Mode: 0644}
+	source["js/new.js"] = &fstest.MapFile{Data: []byte("added()"), Mode: 0644}
+	delete(source, "css/style.css")
+
+	err = builder.Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "index.html,js/app.js,js/new.js"
+	if files := strings.Join(output.Files(), ","); files != expected {
+		t.Errorf("Files() after rebuild = %s, expected %s", files, expected)
+	}
+
+	if content, _ := output.Read("js/app.js"); string(content) != "app(2)" {
+		t.Errorf("Read() after rebuild = %q, expected updated content", content)
+	}
+
+	if inits != 1 {
+		t.Errorf("expected plugin initialized once, got %d", inits)
+	}
+
+}
+
+func TestMemoryBuildCancellation(t *testing.T) {
+
+	var inits, shutdowns int
+	block := false
+	source := fstest.MapFS{
+		"js/app.js": {Data: []byte("app()"), Mode: 0644},
+	}
+
+	// Plugin waits for the timeout when blocked, after writing part of the output
+	plugin := countingPlugin(&inits, &shutdowns)
+	transform := plugin.Transform
+	plugin.Transform = func(ctx context.Context, options *Options, file *File) error {
+
+		err := transform(ctx, options, file)
+		if err != nil || !block {
+			return err
+		}
+
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	builder, output := memoryBuild(t, source, 50*time.Millisecond, plugin)
+
+	err := builder.Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block = true
+	source["js/app.js"] = &fstest.MapFile{Data: []byte("app(2)"), Mode: 0644}
+
+	err = builder.Build(context.Background())
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+
+	if content, ok := output.Read("js/app.js"); !ok || string(content) != "app()" {
+		t.Errorf("Read() after cancellation = %q, expected the last successful output", content)
+	}
+
+}
+
+func TestProcessCancellation(t *testing.T) {
+
+	var inits, shutdowns int
+	root := t.TempDir()
+	options := &Options{
+		Source:      Source{Path: filepath.Join(root, "src")},
+		Destination: Destination{Path: filepath.Join(root, "dist")},
+		Timeout:     50 * time.Millisecond,
+	}
+
+	system.EnsureDirectory(filepath.Join(options.Source.Path, "app.js"))
+	system.Write(filepath.Join(options.Source.Path, "app.js"), "app()", 0644)
+
+	// Plugin writes the output and then waits for the timeout
+	plugin := countingPlugin(&inits, &shutdowns)
+	transform := plugin.Transform
+	plugin.Transform = func(ctx context.Context, options *Options, file *File) error {
+
+		err := transform(ctx, options, file)
+		if err != nil {
+			return err
+		}
+
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	builder := NewBuilder(options)
+	builder.AddPlugin(plugin)
+	defer builder.Shutdown()
+
+	err := builder.Build(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Build() = %v, expected deadline exceeded", err)
+	}
+
+	if system.Exist(filepath.Join(options.Destination.Path, "app.js")) {
+		t.Errorf("expected partial output removed on cancellation")
+	}
+
+}
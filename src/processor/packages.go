@@ -1,11 +1,10 @@
 package processor
 
-// Packages list
+// FindPackages retrieves the full list of detected packages
 // The packages index contains information of all files that resolves to a destination
-var _packages []*File
+func (b *Builder) FindPackages() []*File {
 
-// FindPackages retrieves the full list of detected packages
-func FindPackages(options *Options) []*File {
+	options := b.Options
 
 	var packages []*File
 
@@ -14,7 +13,7 @@ func FindPackages(options *Options) []*File {
 	// These files cannot have an exclusive package because they are dependencies
 	ignore := make(map[string]bool)
 
-	for _, file := range _files {
+	for _, file := range b.files {
 		for _, related := range file.Related {
 			if related.Dependency {
 				ignore[related.File.Path] = true
@@ -22,7 +21,7 @@ func FindPackages(options *Options) []*File {
 		}
 	}
 
-	for _, file := range _files {
+	for _, file := range b.files {
 
 		// Prevent if should be ignored
 		if _, ok := ignore[file.Path]; ok {
@@ -36,15 +35,55 @@ func FindPackages(options *Options) []*File {
 	}
 
 	// Replace current index
-	_packages = packages
+	// Referenced packages are sorted first to allow using their final output
+	b.packages = SortPackages(packages)
 
-	return _packages
+	return b.packages
+}
+
+// SortPackages orders the packages so non dependency related packages come before the package itself
+func SortPackages(packages []*File) []*File {
+
+	var sorted []*File
+	var visit func(file *File)
+
+	visited := make(map[string]bool)
+	included := make(map[string]bool)
+
+	for _, file := range packages {
+		included[file.Path] = true
+	}
+
+	visit = func(file *File) {
+
+		if _, ok := visited[file.Path]; ok {
+			return
+		}
+
+		visited[file.Path] = true
+
+		for _, related := range file.FindRelated(false) {
+			if !related.Dependency && included[related.File.Path] {
+				visit(related.File)
+			}
+		}
+
+		sorted = append(sorted, file)
+	}
+
+	for _, file := range packages {
+		visit(file)
+	}
+
+	return sorted
 }
 
 // FindPackage retrieves the related package from given path
-func FindPackage(options *Options, path string) *File {
+func (b *Builder) FindPackage(path string) *File {
 
-	for _, file := range _packages {
+	options := b.Options
+
+	for _, file := range b.packages {
 
 		source := options.ToSource(path)
 		destination := options.ToDestination(path)
@@ -64,3 +103,13 @@ func FindPackage(options *Options, path string) *File {
 
 	return &File{}
 }
+
+// FindPackages retrieves the list of detected packages with the builder bound to the options
+func FindPackages(options *Options) []*File {
+	return options.Builder().FindPackages()
+}
+
+// FindPackage retrieves the related package from given path with the builder bound to the options
+func FindPackage(options *Options, path string) *File {
+	return options.Builder().FindPackage(path)
+}
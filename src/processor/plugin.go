@@ -1,5 +1,7 @@
 package processor
 
+import "context"
+
 // Init function
 // Used to start up the plugin and check for their dependencies
 type InitFunc = func(options *Options) error
@@ -18,21 +20,23 @@ type RelatedFunc = func(options *Options, file *File) ([]Related, error)
 
 // Transform function
 // Used to transform the file content to the final format by applying compilation if necessary
-type TransformFunc = func(options *Options, file *File) error
+// Context is cancelled on timeout or interruption, so external commands should respect it
+type TransformFunc = func(ctx context.Context, options *Options, file *File) error
 
 // Optimize function
 // Used to run optimizations algorithms to compress file
-type OptimizeFunc = func(options *Options, file *File) error
+// Context is cancelled on timeout or interruption, so external commands should respect it
+type OptimizeFunc = func(ctx context.Context, options *Options, file *File) error
 
 // Plugin struct
 type Plugin struct {
-	Namespace   string
-	Extensions  []string
-	Initialized bool
-	Init        InitFunc
-	Shutdown    ShutdownFunc
-	Resolve     ResolveFunc
-	Related     RelatedFunc
-	Transform   TransformFunc
-	Optimize    OptimizeFunc
+	Namespace  string
+	Extensions []string
+	Tools      []Tool
+	Init       InitFunc
+	Shutdown   ShutdownFunc
+	Resolve    ResolveFunc
+	Related    RelatedFunc
+	Transform  TransformFunc
+	Optimize   OptimizeFunc
 }
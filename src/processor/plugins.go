@@ -2,34 +2,35 @@ package processor
 
 import "slices"
 
-// Plugins index list
-// Hold the registered plugins, used for processing
-var _plugins []*Plugin
-
 // AddPlugin add a new plugin to the index
-func AddPlugin(plugin *Plugin) {
-	_plugins = append(_plugins, plugin)
+func (b *Builder) AddPlugin(plugin *Plugin) {
+	b.plugins = append(b.plugins, plugin)
 }
 
 // RemovePlugin removes all plugins from index that match the given namespace
-func RemovePlugin(namespace string) {
+func (b *Builder) RemovePlugin(namespace string) {
 
 	var list []*Plugin
 
-	for _, _plugin := range _plugins {
+	for _, _plugin := range b.plugins {
 		if namespace != _plugin.Namespace {
 			list = append(list, _plugin)
 		}
 	}
 
-	_plugins = list
+	b.plugins = list
+
+}
 
+// GetPlugins retrieves the list of registered plugins
+func (b *Builder) GetPlugins() []*Plugin {
+	return b.plugins
 }
 
 // GetPlugin retrieves the first found plugin for the given extension
-func GetPlugin(extension string) *Plugin {
+func (b *Builder) GetPlugin(extension string) *Plugin {
 
-	for _, plugin := range _plugins {
+	for _, plugin := range b.plugins {
 
 		// Extension plugin
 		if slices.Contains(plugin.Extensions, extension) {
@@ -46,3 +47,23 @@ func GetPlugin(extension string) *Plugin {
 
 	return &Plugin{}
 }
+
+// AddPlugin add a new plugin to the default builder
+func AddPlugin(plugin *Plugin) {
+	Default.AddPlugin(plugin)
+}
+
+// RemovePlugin removes all plugins from the default builder that match the given namespace
+func RemovePlugin(namespace string) {
+	Default.RemovePlugin(namespace)
+}
+
+// GetPlugins retrieves the list of plugins registered on the default builder
+func GetPlugins() []*Plugin {
+	return Default.GetPlugins()
+}
+
+// GetPlugin retrieves the first found plugin on the default builder for the given extension
+func GetPlugin(extension string) *Plugin {
+	return Default.GetPlugin(extension)
+}
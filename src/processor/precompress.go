@@ -0,0 +1,129 @@
+package processor
+
+import (
+	"slices"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Pre-compressed formats and their file extensions
+var PrecompressFormats = map[string]string{
+	"gzip":   ".gz",
+	"brotli": ".br",
+}
+
+// Text file extensions that can be pre-compressed
+var PrecompressExtensions = []string{
+	".html", ".htm", ".css", ".js", ".mjs", ".json",
+	".xml", ".svg", ".txt", ".webmanifest", ".map",
+}
+
+// CanPrecompress return if destination path is a text output that can be pre-compressed
+func CanPrecompress(options *Options, path string) bool {
+
+	if !slices.Contains(PrecompressExtensions, system.Extension(path)) {
+		return false
+	}
+
+	return options.ShouldPrecompress(path)
+}
+
+// PrecompressRelated returns the list of pre-compressed files generated from the file destination
+func PrecompressRelated(options *Options, file *File) []Related {
+
+	var related []Related
+
+	if !CanPrecompress(options, file.Destination) {
+		return related
+	}
+
+	for _, format := range options.Precompress.Formats {
+
+		extension, ok := PrecompressFormats[format]
+		if !ok {
+			continue
+		}
+
+		related = append(related, Related{
+			Type:       "compressed",
+			Dependency: true,
+			Source:     "",
+			Path:       file.File + extension,
+			File: &File{
+				Path:        file.Path + extension,
+				Destination: file.Destination + extension,
+				Root:        file.Root,
+				Location:    file.Location + extension,
+				Folder:      file.Folder,
+				File:        file.File + extension,
+				Name:        file.File,
+				Extension:   extension,
+				Permission:  file.Permission,
+			},
+		})
+
+	}
+
+	return related
+}
+
+// CreatePrecompressed generates the pre-compressed copies of the package outputs
+func CreatePrecompressed(options *Options, file *File) error {
+
+	outputs := []string{file.Destination, file.Destination + ".map"}
+
+	for _, related := range file.Related {
+		if related.Dependency && related.Source == "" && related.Type != "compressed" {
+			outputs = append(outputs, related.File.Destination)
+		}
+	}
+
+	for _, output := range outputs {
+
+		if output == "" || !system.Exist(output) {
+			continue
+		}
+		if !CanPrecompress(options, output) {
+			continue
+		}
+
+		// Small files does not benefit from compression
+		// Also make sure to remove previous copies if any
+		small := system.Size(output) < options.Precompress.Threshold
+
+		for _, format := range options.Precompress.Formats {
+
+			extension, ok := PrecompressFormats[format]
+			if !ok {
+				continue
+			}
+
+			destination := output + extension
+
+			if small {
+				err := system.Delete(destination)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			var err error
+
+			switch format {
+			case "gzip":
+				err = system.Gzip(output, destination)
+			case "brotli":
+				err = system.Brotli(output, destination)
+			}
+
+			if err != nil {
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+}
@@ -1,11 +1,54 @@
 package processor
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/mateussouzaweb/compactor/src/errors"
 	"github.com/mateussouzaweb/compactor/src/system"
 )
 
 // Process execute file packaging by running plugin methods
-func Process(options *Options, file *File) error {
+// When the context is cancelled or the timeout expires, partial outputs are removed
+// Final outputs are exported to the destination output when available
+func (b *Builder) Process(ctx context.Context, file *File) error {
+
+	options := b.Options
+
+	// Stop before starting when already cancelled
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	// On cancellation, partial outputs are removed from the destination path
+	// Destination output is not updated, so it keeps the last exported files
+	err := b.process(ctx, file)
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("%w: %v", ctx.Err(), err)
+		errors.Join(&err, func() error {
+			return Delete(options, file)
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	return b.export(file)
+}
+
+// process runs the plugin methods on the file
+func (b *Builder) process(ctx context.Context, file *File) error {
+
+	options := b.Options
 
 	// Make sure folder exists to avoid issues
 	err := system.EnsureDirectory(file.Destination)
@@ -14,12 +57,18 @@ func Process(options *Options, file *File) error {
 	}
 
 	// Find the appropriated plugin by detecting extension
-	plugin := GetPlugin(file.Extension)
+	plugin := b.GetPlugin(file.Extension)
 
 	// Init action
-	if !plugin.Initialized {
+	// External tools are checked first to avoid obscure command errors
+	if !b.initialized[plugin] {
+		err = b.CheckPlugin(plugin)
+		if err != nil {
+			return err
+		}
+
 		err = plugin.Init(options)
-		plugin.Initialized = true
+		b.initialized[plugin] = true
 
 		if err != nil {
 			return err
@@ -39,79 +88,130 @@ func Process(options *Options, file *File) error {
 	// }
 
 	// Transform action
-	err = plugin.Transform(options, file)
+	err = plugin.Transform(ctx, options, file)
 	if err != nil {
 		return err
 	}
 
 	// Optimize action
-	return plugin.Optimize(options, file)
+	err = plugin.Optimize(ctx, options, file)
+	if err != nil {
+		return err
+	}
+
+	// Hash from the final output content
+	err = UpdateHash(options, file)
+	if err != nil {
+		return err
+	}
+
+	// Pre-compressed copies from final outputs
+	return CreatePrecompressed(options, file)
+}
+
+// Process execute file packaging with the builder bound to the options
+func Process(ctx context.Context, options *Options, file *File) error {
+	return options.Builder().Process(ctx, file)
 }
 
 // Delete removes the destination file(s) for given file
+// Names are derived from the final destination, so hashed names and their variations are included
 func Delete(options *Options, file *File) error {
 
-	destination := file.Destination
-	toDelete := []string{destination}
+	for _, path := range outputPaths(file) {
 
-	// File checksum history
-	for _, checksum := range file.Checksum {
-		path := options.ToHashed(destination, checksum)
-		clean := options.ToNonHashed(destination, checksum)
-		toDelete = append(toDelete, path, clean)
-	}
+		if !system.Exist(path) {
+			continue
+		}
 
-	// Related auto generated dependencies
-	for _, related := range file.Related {
-		if related.Dependency && related.Source == "" {
+		err := system.Delete(path)
+		if err != nil {
+			return err
+		}
 
-			destination := related.File.Destination
-			toDelete = append(toDelete, destination)
+	}
 
-			// Variations from related file checksum
-			for _, checksum := range related.File.Checksum {
-				path := options.ToHashed(destination, checksum)
-				clean := options.ToNonHashed(destination, checksum)
-				toDelete = append(toDelete, path, clean)
-			}
+	return nil
+}
 
-			// Variations from main file checksum
-			for _, checksum := range file.Checksum {
-				path := options.ToHashed(destination, checksum)
-				clean := options.ToNonHashed(destination, checksum)
-				toDelete = append(toDelete, path, clean)
-			}
+// Shutdown make sure every plugin has properly shutdown
+// The destination output is closed and temporary working folders are removed
+func (b *Builder) Shutdown() error {
 
+	for _, plugin := range b.plugins {
+		if b.initialized[plugin] {
+			delete(b.initialized, plugin)
+			err := plugin.Shutdown(b.Options)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	for _, file := range toDelete {
-
-		if !system.Exist(file) {
-			continue
+	if b.Options != nil && b.Options.Destination.Output != nil {
+		err := b.Options.Destination.Output.Close()
+		if err != nil {
+			return err
 		}
+	}
 
-		err := system.Delete(file)
+	for _, path := range b.temporary {
+		err := system.DeleteDirectory(path)
 		if err != nil {
 			return err
 		}
-
 	}
 
 	return nil
 }
 
-// Shutdown make sure every plugin has properly shutdown
+// Shutdown make sure every plugin of the builder bound to the options has properly shutdown
 func Shutdown(options *Options) error {
+	return options.Builder().Shutdown()
+}
 
-	for _, plugin := range _plugins {
-		if plugin.Initialized {
-			err := plugin.Shutdown(options)
-			if err != nil {
-				return err
-			}
+// outputPaths retrieves the possible files written to the destination for given file, existing or not
+// Includes the destination itself, generated variations, related generated dependencies and pre-compressed copies
+func outputPaths(file *File) []string {
+
+	var paths []string
+
+	candidates := []string{file.Destination}
+	for _, suffix := range HashSuffixes {
+		candidates = append(candidates, file.Destination+suffix)
+	}
+
+	for _, related := range file.Related {
+		if related.Dependency && related.Source == "" && related.Type != "compressed" {
+			candidates = append(candidates, related.File.Destination)
 		}
 	}
 
-	return nil
+	for _, path := range candidates {
+		if path == "" || slices.Contains(paths, path) {
+			continue
+		}
+
+		paths = append(paths, path)
+
+		for _, format := range slices.Sorted(maps.Keys(PrecompressFormats)) {
+			paths = append(paths, path+PrecompressFormats[format])
+		}
+	}
+
+	return paths
+}
+
+// Outputs retrieves the existing files written to the destination for given file
+func Outputs(file *File) []string {
+
+	var outputs []string
+
+	for _, path := range outputPaths(file) {
+		if system.Exist(path) {
+			outputs = append(outputs, path)
+		}
+	}
+
+	return outputs
 }
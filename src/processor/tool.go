@@ -0,0 +1,212 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mateussouzaweb/compactor/src/system"
+)
+
+// Tool struct
+// Optional tools are only used on specific features and never block the plugin
+type Tool struct {
+	Name     string
+	Module   bool
+	Optional bool
+	Version  []string
+	Install  string
+}
+
+// ToolStatus struct
+type ToolStatus struct {
+	Tool    Tool
+	Found   bool
+	Path    string
+	Version string
+}
+
+// FindNodeModules retrieves the node modules folder that contains the module
+// Configured folder has priority, then local folders from source path up to the root, then the global folder
+func FindNodeModules(options *Options, module string) string {
+
+	if options.Tools.NodeModules != "" {
+		return options.Tools.NodeModules
+	}
+
+	path := options.Source.Path
+	for path != "" {
+
+		folder := filepath.Join(path, "node_modules")
+		if system.Exist(filepath.Join(folder, module)) {
+			return folder
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+
+		path = parent
+	}
+
+	folder, _ := system.NodeModules()
+
+	return folder
+}
+
+// ResolveTool retrieves the executable path of the tool
+// Configured path has priority, then the binary from local node modules, then the tool name to be found on PATH
+func ResolveTool(options *Options, tool Tool) string {
+
+	if path, ok := options.Tools.Path[tool.Name]; ok && path != "" {
+		return path
+	}
+
+	path := options.Source.Path
+	for path != "" {
+
+		binary := filepath.Join(path, "node_modules", ".bin", tool.Name)
+		if system.Exist(binary) {
+			return binary
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+
+		path = parent
+	}
+
+	return tool.Name
+}
+
+// ExecTool runs the tool with given arguments
+// Configured extra arguments are added before the given arguments
+func ExecTool(ctx context.Context, options *Options, tool Tool, args ...string) (string, error) {
+
+	command := ResolveTool(options, tool)
+	args = append(slices.Clone(options.Tools.Args[tool.Name]), args...)
+
+	return system.ExecContext(ctx, command, options.Tools.Env[tool.Name], args...)
+}
+
+// CheckTool detects if the external tool is available and retrieves its version
+// Results are cached on the builder to avoid running the same version command for every plugin that depends on the tool
+func (b *Builder) CheckTool(tool Tool) ToolStatus {
+
+	options := b.Options
+	if status, ok := b.tools[tool.Name]; ok {
+		return status
+	}
+
+	status := ToolStatus{
+		Tool: tool,
+	}
+
+	if tool.Module {
+
+		path := filepath.Join(FindNodeModules(options, tool.Name), tool.Name)
+		manifest := filepath.Join(path, "package.json")
+
+		if system.Exist(manifest) {
+
+			content, _ := system.Read(manifest)
+			data := struct {
+				Version string `json:"version"`
+			}{}
+
+			json.Unmarshal([]byte(content), &data)
+
+			status.Found = true
+			status.Path = path
+			status.Version = data.Version
+
+		}
+
+	} else {
+
+		path, err := system.Which(ResolveTool(options, tool))
+
+		if err == nil {
+
+			status.Found = true
+			status.Path = path
+
+			output, _ := system.ExecContext(context.Background(), path, options.Tools.Env[tool.Name], tool.Version...)
+			for _, line := range strings.Split(output, "\n") {
+				if strings.TrimSpace(line) != "" {
+					status.Version = strings.TrimSpace(line)
+					break
+				}
+			}
+
+		}
+
+	}
+
+	b.tools[tool.Name] = status
+
+	return status
+}
+
+// CheckPlugin checks if every external tool required by the plugin is available
+// The error lists the missing tools with their install hints
+func (b *Builder) CheckPlugin(plugin *Plugin) error {
+
+	var missing []string
+
+	for _, tool := range plugin.Tools {
+		if !tool.Optional && !b.CheckTool(tool).Found {
+			missing = append(missing, fmt.Sprintf("%s (%s)", tool.Name, tool.Install))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf(
+			"plugin %s requires missing tools: %s",
+			plugin.Namespace,
+			strings.Join(missing, ", "),
+		)
+	}
+
+	return nil
+}
+
+// DisableUnavailable removes the plugins with missing external tools from index
+// Files are then processed by the next plugin that matches the extension or the generic plugin
+func (b *Builder) DisableUnavailable() []string {
+
+	var disabled []string
+
+	for _, plugin := range b.plugins {
+		if b.CheckPlugin(plugin) != nil {
+			disabled = append(disabled, plugin.Namespace)
+		}
+	}
+
+	for _, namespace := range disabled {
+		b.RemovePlugin(namespace)
+	}
+
+	return disabled
+}
+
+// CheckTool detects if the external tool is available with the builder bound to the options
+func CheckTool(options *Options, tool Tool) ToolStatus {
+	return options.Builder().CheckTool(tool)
+}
+
+// CheckPlugin checks if every external tool required by the plugin is available with the builder bound to the options
+func CheckPlugin(options *Options, plugin *Plugin) error {
+	return options.Builder().CheckPlugin(plugin)
+}
+
+// DisableUnavailable removes the plugins with missing external tools from the builder bound to the options
+func DisableUnavailable(options *Options) []string {
+	return options.Builder().DisableUnavailable()
+}
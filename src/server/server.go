@@ -1,8 +1,12 @@
 package server
 
 import (
+	"io/fs"
+	"mime"
 	"net/http"
-	"path/filepath"
+	"os"
+	"path"
+	"strconv"
 	"strings"
 
 	"github.com/mateussouzaweb/compactor/src/system"
@@ -11,20 +15,111 @@ import (
 // RequestCallback type
 type RequestCallback func(uri string) error
 
-// Server start a file server with given path and port
-func Start(root string, port string, onRequest RequestCallback) error {
+// Encodings of pre-compressed files, in order of preference
+var Encodings = []struct {
+	Name      string
+	Extension string
+}{
+	{Name: "br", Extension: ".br"},
+	{Name: "gzip", Extension: ".gz"},
+}
+
+// Quality retrieves the q-value of the encoding on the Accept-Encoding header
+// The wildcard applies to encodings not explicitly listed, and missing encodings are not accepted
+func Quality(accept string, name string) float64 {
+
+	wildcard := 0.0
+
+	for _, token := range strings.Split(accept, ",") {
+
+		parts := strings.Split(token, ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := 1.0
+
+		for _, param := range parts[1:] {
+			key, number, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.ToLower(strings.TrimSpace(key)) == "q" {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+				if err != nil {
+					parsed = 0
+				}
+				value = parsed
+			}
+		}
+
+		if coding == name {
+			return value
+		}
+		if coding == "*" {
+			wildcard = value
+		}
+
+	}
+
+	return wildcard
+}
+
+// ServeFile replies with the pre-compressed copy of the file when client accepts its encoding
+// The encoding with highest q-value is used, with ties resolved by the encodings preference
+func ServeFile(response http.ResponseWriter, request *http.Request, root fs.FS, name string) {
+
+	accept := request.Header.Get("Accept-Encoding")
+	response.Header().Add("Vary", "Accept-Encoding")
+
+	// Index requests are redirected to the folder path
+	if strings.HasSuffix(request.URL.Path, "/index.html") {
+		accept = ""
+	}
+
+	selected := -1
+	best := 0.0
+
+	for index, encoding := range Encodings {
+
+		quality := Quality(accept, encoding.Name)
+		if quality <= best {
+			continue
+		}
+		if !exists(root, name+encoding.Extension) {
+			continue
+		}
+
+		selected = index
+		best = quality
 
-	// Make sure root folder exists
-	err := system.EnsureDirectory(root)
-	if err != nil {
-		return err
 	}
 
-	// Attach server handle
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", func(response http.ResponseWriter, request *http.Request) {
+	if selected != -1 {
+
+		encoding := Encodings[selected]
+		contentType := mime.TypeByExtension(system.Extension(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		response.Header().Set("Content-Type", contentType)
+		response.Header().Set("Content-Encoding", encoding.Name)
+		http.ServeFileFS(response, request, root, name+encoding.Extension)
+		return
+
+	}
+
+	http.ServeFileFS(response, request, root, name)
+}
+
+// exists check if file exists on the filesystem
+func exists(root fs.FS, name string) bool {
+	_, err := fs.Stat(root, name)
+	return err == nil
+}
+
+// Handler creates the file server handler for the given filesystem
+// Works with any filesystem, like the destination folder or compiled files in memory
+func Handler(root fs.FS, onRequest RequestCallback) http.Handler {
 
-		uri := filepath.Clean(request.URL.Path)
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		uri := path.Clean("/" + request.URL.Path)
 		uri = strings.TrimSuffix(uri, "/")
 
 		// Make sure we are requesting a file when trying to get an unknown uri
@@ -40,23 +135,33 @@ func Start(root string, port string, onRequest RequestCallback) error {
 			return
 		}
 
-		path := filepath.Join(root, strings.TrimPrefix(uri, "/"))
-		indexFile := filepath.Join(root, "index.html")
+		name := strings.TrimPrefix(uri, "/")
+		indexFile := "index.html"
 
 		// If not exists requested path, then try to reply with root index.html file
-		if !system.Exist(path) {
-			if !system.Exist(indexFile) {
+		if !exists(root, name) {
+			if !exists(root, indexFile) {
 				http.Error(response, http.StatusText(500), 500)
 			} else {
-				http.ServeFile(response, request, indexFile)
+				ServeFile(response, request, root, indexFile)
 			}
 			return
 		}
 
 		// If everything ok, just serve the file
-		http.ServeFile(response, request, path)
+		ServeFile(response, request, root, name)
 
 	})
+}
+
+// Server start a file server with given path and port
+func Start(root string, port string, onRequest RequestCallback) error {
+
+	// Make sure root folder exists
+	err := system.EnsureDirectory(root)
+	if err != nil {
+		return err
+	}
 
-	return http.ListenAndServe(":"+port, mux)
+	return http.ListenAndServe(":"+port, Handler(os.DirFS(root), onRequest))
 }
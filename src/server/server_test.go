@@ -0,0 +1,31 @@
+package server
+
+import "testing"
+
+func TestQuality(t *testing.T) {
+
+	tests := []struct {
+		accept   string
+		name     string
+		expected float64
+	}{
+		{"gzip, br", "gzip", 1},
+		{"gzip, br", "br", 1},
+		{"gzip;q=0, br", "gzip", 0},
+		{"gzip;q=0.5", "gzip", 0.5},
+		{"GZIP; Q=0.8", "gzip", 0.8},
+		{"x-gzip", "gzip", 0},
+		{"deflate", "br", 0},
+		{"*;q=0.3", "br", 0.3},
+		{"br;q=0, *", "br", 0},
+		{"", "gzip", 0},
+	}
+
+	for _, test := range tests {
+		result := Quality(test.accept, test.name)
+		if result != test.expected {
+			t.Errorf("Quality(%q, %q) = %v, expected %v", test.accept, test.name, result, test.expected)
+		}
+	}
+
+}
@@ -1,21 +1,44 @@
 package system
 
 import (
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"math/rand"
+	"mime"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
 )
 
 // Exec run command with given arguments
 func Exec(cmd string, args ...string) (string, error) {
+	return ExecContext(context.Background(), cmd, nil, args...)
+}
+
+// ExecContext run command with given arguments and additional environment variables
+// The command is killed when the context is done
+func ExecContext(ctx context.Context, cmd string, env []string, args ...string) (string, error) {
+
+	result := exec.CommandContext(ctx, cmd, args...)
+	result.WaitDelay = time.Second
+	killGroup(result)
+
+	if len(env) > 0 {
+		result.Env = append(os.Environ(), env...)
+	}
 
-	result := exec.Command(cmd, args...)
 	output, err := result.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("command error: %s ...\n%v\n%s", result.Args, err, string(output))
@@ -24,11 +47,62 @@ func Exec(cmd string, args ...string) (string, error) {
 	return string(output), nil
 }
 
+// StartContext starts the command in background with additional environment variables
+// The command and its children processes are killed when the context is done
+func StartContext(ctx context.Context, cmd string, env []string, args ...string) (*exec.Cmd, error) {
+
+	result := exec.CommandContext(ctx, cmd, args...)
+	result.WaitDelay = time.Second
+	result.Env = append(os.Environ(), env...)
+	killGroup(result)
+
+	err := result.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Which retrieve the full path of the executable command
+func Which(cmd string) (string, error) {
+	return exec.LookPath(cmd)
+}
+
+// NodeModules retrieve the path of the global node modules folder
+func NodeModules() (string, error) {
+
+	output, err := Exec("npm", "root", "-g")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
 // Checksum retrieve the checksum for given content
 func Checksum(content string) (string, error) {
+	return checksum(strings.NewReader(content))
+}
+
+// FileChecksum retrieve the checksum for the file content by streaming it
+func FileChecksum(file string) (string, error) {
+
+	handler, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+
+	defer handler.Close()
+
+	return checksum(handler)
+}
+
+// checksum retrieve the checksum for the content of the reader
+func checksum(reader io.Reader) (string, error) {
 
 	sum := md5.New()
-	_, err := io.WriteString(sum, content)
+	_, err := io.Copy(sum, reader)
 	if err != nil {
 		return "", err
 	}
@@ -39,6 +113,103 @@ func Checksum(content string) (string, error) {
 	return hash, err
 }
 
+// hasher creates the hash writer for the algorithm
+func hasher(algorithm string) hash.Hash {
+
+	switch algorithm {
+	case "sha256":
+		return sha256.New()
+	case "xxhash":
+		return xxhash.New()
+	default:
+		return md5.New()
+	}
+
+}
+
+// encode retrieve the hexadecimal hash from the sum, trimmed to length
+func encode(sum []byte, length int) string {
+
+	hash := hex.EncodeToString(sum)
+	if length > 0 && length < len(hash) {
+		hash = hash[:length]
+	}
+
+	return hash
+}
+
+// Hash retrieve the hexadecimal hash for given content with the algorithm and length
+// Supported algorithms are md5, sha256 and xxhash
+func Hash(content string, algorithm string, length int) string {
+
+	sum := hasher(algorithm)
+	io.WriteString(sum, content)
+
+	return encode(sum.Sum(nil), length)
+}
+
+// HashFiles retrieve the hexadecimal hash for the concatenated content of the files
+// Missing files are ignored, like empty content
+func HashFiles(files []string, algorithm string, length int) (string, error) {
+
+	sum := hasher(algorithm)
+
+	for _, file := range files {
+
+		handler, err := os.Open(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		_, err = io.Copy(sum, handler)
+		handler.Close()
+
+		if err != nil {
+			return "", err
+		}
+
+	}
+
+	return encode(sum.Sum(nil), length), nil
+}
+
+// Integrity retrieve the integrity hash for given content with the algorithm: sha256, sha384 or sha512
+func Integrity(content string, algorithm string) string {
+
+	var sum []byte
+
+	switch algorithm {
+	case "sha256":
+		hash := sha256.Sum256([]byte(content))
+		sum = hash[:]
+	case "sha512":
+		hash := sha512.Sum512([]byte(content))
+		sum = hash[:]
+	default:
+		algorithm = "sha384"
+		hash := sha512.Sum384([]byte(content))
+		sum = hash[:]
+	}
+
+	return algorithm + "-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+// DataURI retrieve the base64 data URI for given content with the mime type of the file
+func DataURI(content string, file string) string {
+
+	mimeType := mime.TypeByExtension(Extension(file))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	mimeType = strings.ReplaceAll(mimeType, " ", "")
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+
+	return "data:" + mimeType + ";base64," + encoded
+}
+
 // RandomString generates a random string from give size
 func RandomString(n int) string {
 
@@ -57,6 +228,11 @@ func TemporaryFile(file string) string {
 	return filepath.Join(os.TempDir(), fileName)
 }
 
+// TemporaryDirectory creates and return a new temporary directory path
+func TemporaryDirectory(name string) (string, error) {
+	return os.MkdirTemp("", name+"-")
+}
+
 // TemporaryPort will retry and return a free TCP port on the system
 func TemporaryPort() (string, error) {
 
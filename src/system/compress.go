@@ -0,0 +1,96 @@
+package system
+
+import (
+	"bytes"
+	"compress/gzip"
+
+	"github.com/andybalholm/brotli"
+)
+
+// gzipContent compress the content with the best compression level
+func gzipContent(content string) (string, error) {
+
+	var buffer bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buffer, gzip.BestCompression)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = writer.Write([]byte(content))
+	if err != nil {
+		return "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// Gzip compress the origin file into destination with the best compression level
+func Gzip(origin string, destination string) error {
+
+	content, err := Read(origin)
+	if err != nil {
+		return err
+	}
+
+	perm, err := Permissions(origin)
+	if err != nil {
+		return err
+	}
+
+	compressed, err := gzipContent(content)
+	if err != nil {
+		return err
+	}
+
+	return Write(destination, compressed, perm)
+}
+
+// GzipSize retrieve the size in bytes of the file after gzip compression
+func GzipSize(file string) (int64, error) {
+
+	content, err := Read(file)
+	if err != nil {
+		return 0, err
+	}
+
+	compressed, err := gzipContent(content)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(compressed)), nil
+}
+
+// Brotli compress the origin file into destination with the best compression level
+func Brotli(origin string, destination string) error {
+
+	content, err := Read(origin)
+	if err != nil {
+		return err
+	}
+
+	perm, err := Permissions(origin)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	writer := brotli.NewWriterLevel(&buffer, brotli.BestCompression)
+
+	_, err = writer.Write([]byte(content))
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return Write(destination, buffer.String(), perm)
+}
@@ -1,9 +1,13 @@
 package system
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -31,6 +35,62 @@ func Permissions(path string) (fs.FileMode, error) {
 	return perm, nil
 }
 
+// Size retrieve the size in bytes of the file
+func Size(path string) int64 {
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+
+	return info.Size()
+}
+
+// ParseSize converts a human readable size, like 150KB or 2MB, into bytes
+func ParseSize(value string) (int64, error) {
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+	units := []struct {
+		Suffix     string
+		Multiplier int64
+	}{
+		{"GB", 1024 * 1024 * 1024},
+		{"MB", 1024 * 1024},
+		{"KB", 1024},
+		{"B", 1},
+	}
+
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.Suffix) {
+			number, err := strconv.ParseFloat(strings.TrimSuffix(value, unit.Suffix), 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size: %s", value)
+			}
+			return int64(number * float64(unit.Multiplier)), nil
+		}
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+
+	return number, nil
+}
+
+// FormatSize converts the size in bytes into a human readable size
+func FormatSize(size int64) string {
+
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.2fMB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.2fKB", float64(size)/1024)
+	}
+
+	return fmt.Sprintf("%dB", size)
+}
+
 // Read retrieve content from file
 func Read(file string) (string, error) {
 
@@ -43,10 +103,49 @@ func Read(file string) (string, error) {
 }
 
 // Write content on file
+// Content is written into a temporary file that replaces the file, so readers never see partial content
 func Write(file string, content string, perm fs.FileMode) error {
 
-	err := os.WriteFile(file, []byte(content), perm)
+	temporary := filepath.Join(Dir(file), "."+File(file)+"."+RandomString(8)+".tmp")
+
+	err := os.WriteFile(temporary, []byte(content), perm)
+	if err != nil {
+		os.Remove(temporary)
+		return err
+	}
+
+	err = os.Rename(temporary, file)
 	if err != nil {
+		os.Remove(temporary)
+		return err
+	}
+
+	return nil
+}
+
+// Stream writes the content of the reader on file
+// Writes go to a temporary file renamed over the target, so readers never see a partial file
+func Stream(file string, reader io.Reader, perm fs.FileMode) error {
+
+	temporary := filepath.Join(Dir(file), "."+File(file)+"."+RandomString(8)+".tmp")
+	target, err := os.OpenFile(temporary, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(target, reader)
+	if err == nil {
+		err = target.Close()
+	} else {
+		target.Close()
+	}
+
+	if err == nil {
+		err = os.Rename(temporary, file)
+	}
+
+	if err != nil {
+		os.Remove(temporary)
 		return err
 	}
 
@@ -56,19 +155,77 @@ func Write(file string, content string, perm fs.FileMode) error {
 // Copy the origin file into destination
 func Copy(origin string, destination string) error {
 
-	content, err := Read(origin)
+	perm, err := Permissions(origin)
 	if err != nil {
 		return err
 	}
 
-	perm, err := Permissions(origin)
+	source, err := os.Open(origin)
 	if err != nil {
 		return err
 	}
 
-	err = Write(destination, content, perm)
+	defer source.Close()
 
-	return err
+	return Stream(destination, source, perm)
+}
+
+// Extract writes every file from the filesystem into the root folder
+// Files are always readable and writable by the owner, so external tools can process them
+// Files on root folder that no longer exist on the filesystem are removed, so the copy always matches
+func Extract(fsys fs.FS, root string) error {
+
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		source, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+
+		defer source.Close()
+
+		file := filepath.Join(root, filepath.FromSlash(path))
+		err = EnsureDirectory(file)
+		if err != nil {
+			return err
+		}
+
+		return Stream(file, source, info.Mode().Perm()|0644)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	files, err := List(root)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+
+		name := filepath.ToSlash(Relative(root, file))
+		_, err := fs.Stat(fsys, name)
+
+		if os.IsNotExist(err) {
+			err = os.Remove(file)
+		}
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
 }
 
 // Replace content inside file
@@ -100,6 +257,58 @@ func Delete(file string) error {
 	return nil
 }
 
+// DeleteDirectory remove a directory and all its content
+func DeleteDirectory(path string) error {
+	return os.RemoveAll(path)
+}
+
+// SwapDirectory replaces the destination directory with the origin directory
+// When supported by the platform, both paths are exchanged atomically, so the destination is never missing
+func SwapDirectory(origin string, destination string) error {
+
+	if !Exist(destination) {
+		return os.Rename(origin, destination)
+	}
+
+	swapped, err := exchange(origin, destination)
+	if err != nil {
+		return err
+	}
+
+	// Origin now holds the previous destination
+	if swapped {
+		return os.RemoveAll(origin)
+	}
+
+	return renameDirectory(origin, destination)
+}
+
+// renameDirectory replaces the destination directory with two renames
+// Fallback when atomic exchange is not available, destination is missing for a moment between the renames
+// Previous destination is only removed after the swap, being restored on failure
+func renameDirectory(origin string, destination string) error {
+
+	previous := destination + ".previous"
+
+	err := os.RemoveAll(previous)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(destination, previous)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(origin, destination)
+	if err != nil {
+		os.Rename(previous, destination)
+		return err
+	}
+
+	return os.RemoveAll(previous)
+}
+
 // Rename a file path. Overwrite if already exists
 func Rename(origin string, destination string) error {
 	return os.Rename(origin, destination)
@@ -148,25 +357,62 @@ func Extension(file string) string {
 	return filepath.Ext(file)
 }
 
-// Info read and return file information: content, checksum and permissions
-func Info(file string) (string, string, fs.FileMode) {
+// Info read and return file information: checksum, binary flag and permissions
+// File is read once, the first bytes detect binary content and every byte goes into the checksum
+// Unreadable files default to empty checksum and 0644 permissions
+func Info(file string) (string, bool, fs.FileMode) {
 
-	content, err := Read(file)
+	perm, err := Permissions(file)
 	if err != nil {
-		content = ""
+		perm = fs.FileMode(0644)
 	}
 
-	perm, err := Permissions(file)
+	handler, err := os.Open(file)
 	if err != nil {
-		perm = fs.FileMode(0644)
+		return "", false, perm
+	}
+
+	defer handler.Close()
+
+	head, binary, err := isBinary(handler)
+	if err != nil {
+		return "", false, perm
 	}
 
-	checksum, err := Checksum(content)
+	checksum, err := checksum(io.MultiReader(bytes.NewReader(head), handler))
 	if err != nil {
 		checksum = ""
 	}
 
-	return content, checksum, perm
+	return checksum, binary, perm
+}
+
+// IsBinary detects if file has binary content by looking for null bytes on its first bytes
+func IsBinary(file string) bool {
+
+	handler, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+
+	defer handler.Close()
+
+	_, binary, _ := isBinary(handler)
+
+	return binary
+}
+
+// isBinary reads the first bytes from reader and detects null bytes on them
+// The read bytes are returned, so callers can continue reading the content
+func isBinary(reader io.Reader) ([]byte, bool, error) {
+
+	buffer := make([]byte, 8000)
+	read, err := io.ReadFull(reader, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, false, err
+	}
+
+	return buffer[:read], bytes.IndexByte(buffer[:read], 0) != -1, nil
 }
 
 // Resolve will check paths with possible extensions until file is detected
@@ -0,0 +1,129 @@
+package system
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+
+	tests := []struct {
+		value    string
+		expected int64
+		fails    bool
+	}{
+		{"512", 512, false},
+		{"512B", 512, false},
+		{"150KB", 150 * 1024, false},
+		{"150kb", 150 * 1024, false},
+		{" 1.5MB ", 1536 * 1024, false},
+		{"2GB", 2 * 1024 * 1024 * 1024, false},
+		{"", 0, true},
+		{"KB", 0, true},
+		{"ten", 0, true},
+		{"10TB", 0, true},
+	}
+
+	for _, test := range tests {
+
+		size, err := ParseSize(test.value)
+
+		if test.fails {
+			if err == nil {
+				t.Errorf("ParseSize(%q) = %d, expected error", test.value, size)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseSize(%q) returned error: %v", test.value, err)
+		} else if size != test.expected {
+			t.Errorf("ParseSize(%q) = %d, expected %d", test.value, size, test.expected)
+		}
+
+	}
+
+}
+
+func TestSwapDirectory(t *testing.T) {
+
+	for name, swap := range map[string]func(string, string) error{
+		"swap":   SwapDirectory,
+		"rename": renameDirectory,
+	} {
+
+		root := t.TempDir()
+		origin := filepath.Join(root, "staging")
+		destination := filepath.Join(root, "dist")
+
+		os.MkdirAll(origin, 0755)
+		os.MkdirAll(destination, 0755)
+		os.WriteFile(filepath.Join(origin, "new.txt"), []byte("new"), 0644)
+		os.WriteFile(filepath.Join(destination, "old.txt"), []byte("old"), 0644)
+
+		err := swap(origin, destination)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !Exist(filepath.Join(destination, "new.txt")) || Exist(filepath.Join(destination, "old.txt")) {
+			t.Errorf("%s: destination was not replaced by origin", name)
+		}
+
+		entries, _ := os.ReadDir(root)
+		if len(entries) != 1 {
+			t.Errorf("%s: expected only the destination left, found %d entries", name, len(entries))
+		}
+
+	}
+
+	// Missing destination is created from origin
+	root := t.TempDir()
+	origin := filepath.Join(root, "staging")
+	os.MkdirAll(origin, 0755)
+
+	err := SwapDirectory(origin, filepath.Join(root, "dist"))
+	if err != nil || !Exist(filepath.Join(root, "dist")) || Exist(origin) {
+		t.Errorf("SwapDirectory() to missing destination failed: %v", err)
+	}
+
+}
+
+func TestInfo(t *testing.T) {
+
+	root := t.TempDir()
+	text := filepath.Join(root, "text.txt")
+	binary := filepath.Join(root, "binary.bin")
+	large := filepath.Join(root, "large.txt")
+
+	os.WriteFile(text, []byte("hello"), 0640)
+	os.WriteFile(binary, []byte{'P', 'N', 'G', 0, 1, 2}, 0644)
+	os.WriteFile(large, bytes.Repeat([]byte("a"), 20000), 0644)
+
+	for _, test := range []struct {
+		file   string
+		binary bool
+		perm   fs.FileMode
+	}{
+		{text, false, 0640},
+		{binary, true, 0644},
+		{large, false, 0644},
+	} {
+
+		checksum, binary, perm := Info(test.file)
+		expected, _ := FileChecksum(test.file)
+
+		if checksum != expected || binary != test.binary || perm != test.perm {
+			t.Errorf("Info(%s) = %s, %v, %v, expected %s, %v, %v", filepath.Base(test.file), checksum, binary, perm, expected, test.binary, test.perm)
+		}
+
+	}
+
+	if checksum, binary, perm := Info(filepath.Join(root, "missing")); checksum != "" || binary || perm != 0644 {
+		t.Errorf("Info() of missing file = %s, %v, %v", checksum, binary, perm)
+	}
+
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package system
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killGroup configures the command to run on its own process group
+// When cancelled, the whole group is killed, including children processes of the command
+func killGroup(cmd *exec.Cmd) {
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package system
+
+import (
+	"os/exec"
+)
+
+// killGroup keeps the default behavior of killing only the command process
+func killGroup(cmd *exec.Cmd) {}
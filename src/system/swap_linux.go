@@ -0,0 +1,24 @@
+//go:build linux
+
+package system
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// exchange atomically swaps both paths with renameat2 and RENAME_EXCHANGE
+// Returns false when the kernel or the filesystem does not support the exchange
+func exchange(origin string, destination string) (bool, error) {
+
+	err := unix.Renameat2(unix.AT_FDCWD, origin, unix.AT_FDCWD, destination, unix.RENAME_EXCHANGE)
+	if errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EINVAL) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package system
+
+// exchange is not supported on this platform, so directories are swapped with renames
+func exchange(origin string, destination string) (bool, error) {
+	return false, nil
+}
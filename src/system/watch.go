@@ -13,7 +13,7 @@ type WatchCallback func(path string, action string) error
 // WatchCheckFile will check if file has been changed and run callback once necessary
 func WatchCheckFile(file string, onChange WatchCallback) error {
 
-	_, checksum, _ := Info(file)
+	checksum, _ := FileChecksum(file)
 
 	if current, ok := watchTrack[file]; ok {
 		if current != checksum {
@@ -41,7 +41,7 @@ func Watch(root string, onChange WatchCallback) error {
 	}
 
 	for _, file := range files {
-		_, checksum, _ := Info(file)
+		checksum, _ := FileChecksum(file)
 		watchTrack[file] = checksum
 	}
 